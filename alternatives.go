@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxAlternatives is how many fallback candidates are listed per assignment.
+const maxAlternatives = 3
+
+// slotAlternatives is one drawn assignment with the next-best candidates who could cover it.
+type slotAlternatives struct {
+	Day          string
+	Task         string
+	Assignee     string
+	Eligible     int      // users who passed every constraint when the slot was drawn
+	Alternatives []string // least-loaded eligible users first, at most maxAlternatives
+}
+
+// collectAlternatives builds the alternatives for every draw in the trace, in day-of-week and then
+// task order. Candidates who ended up in the same cell are left out since they cannot cover it.
+// Dedicated, linked and fixed cells are not drawn and so have no entry.
+func collectAlternatives(info Info, schedule map[string]map[string]string, trace *decisionTrace) []slotAlternatives {
+	dayIndex := make(map[string]int)
+	for i, day := range info.DaysOfWeek {
+		dayIndex[day] = i
+	}
+
+	var slots []slotAlternatives
+	for _, decision := range trace.Decisions {
+		if decision.Winner == "" {
+			continue
+		}
+		var eligible []candidateDecision
+		for _, candidate := range decision.Candidates {
+			if candidate.Reason == "" {
+				eligible = append(eligible, candidate)
+			}
+		}
+		sort.SliceStable(eligible, func(i, j int) bool {
+			if eligible[i].Load != eligible[j].Load {
+				return eligible[i].Load < eligible[j].Load
+			}
+			return eligible[i].Name < eligible[j].Name
+		})
+
+		slot := slotAlternatives{Day: decision.Day, Task: decision.Task, Assignee: decision.Winner, Eligible: len(eligible)}
+		for _, candidate := range eligible {
+			if len(slot.Alternatives) == maxAlternatives {
+				break
+			}
+			if !cellHas(schedule[decision.Day][decision.Task], candidate.Name) {
+				slot.Alternatives = append(slot.Alternatives, candidate.Name)
+			}
+		}
+		slots = append(slots, slot)
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].Day != slots[j].Day {
+			return dayIndex[slots[i].Day] < dayIndex[slots[j].Day]
+		}
+		return slots[i].Task < slots[j].Task
+	})
+	return slots
+}
+
+// writeAlternatives writes the alternatives as a Day,Task,Assignee,Eligible,Alternatives CSV.
+func writeAlternatives(slots []slotAlternatives, taskLabel string, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"Day", taskLabel, "Assignee", "Eligible", "Alternatives"})
+	for _, slot := range slots {
+		writer.Write([]string{slot.Day, slot.Task, slot.Assignee, strconv.Itoa(slot.Eligible), strings.Join(slot.Alternatives, assigneeSeparator)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
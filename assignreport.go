@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// assignReportHeader returns the fixed column layout of -assign-report, with taskLabel heading the task
+// column. Columns are only ever appended so spreadsheets built on the report keep working.
+func assignReportHeader(taskLabel string) []string {
+	return []string{"Day", taskLabel, "Assignee", "Pool Size", "Unfilled Reason", "Alternatives"}
+}
+
+// reasonCategories maps the start of an exclusion reason to the category shown in -assign-report.
+var reasonCategories = []struct{ prefix, category string }{
+	{"missing required training", "training"},
+	{"unavailable on", "availability"},
+	{"assigned the same task on", "spacing"},
+	{"assigned the same slot last week", "anti-repeat"},
+	{"assigned this task on", "anti-repeat"},
+	{"distinct-task cap", "variety"},
+	{"time slot overlaps", "overlap"},
+	{"weekly task cap", "cap"},
+	{"workload cap", "cap"},
+}
+
+// reasonCategory returns the category of an exclusion reason, or "other".
+func reasonCategory(reason string) string {
+	for _, rc := range reasonCategories {
+		if strings.HasPrefix(reason, rc.prefix) {
+			return rc.category
+		}
+	}
+	return "other"
+}
+
+// unfilledReason names the category that excluded the most candidates in the slot's last draw, ties
+// broken alphabetically. Slots that were never drawn report "not drawn".
+func unfilledReason(decisions []slotDecision) string {
+	if len(decisions) == 0 {
+		return "not drawn"
+	}
+	counts := make(map[string]int)
+	for _, candidate := range decisions[len(decisions)-1].Candidates {
+		if candidate.Reason != "" && !strings.HasPrefix(candidate.Reason, "already assigned") {
+			counts[reasonCategory(candidate.Reason)]++
+		}
+	}
+	best := ""
+	for category, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && category < best) {
+			best = category
+		}
+	}
+	if best == "" {
+		return "no candidates"
+	}
+	return best
+}
+
+// writeAssignReport writes one row per day and task that runs that day: the assignees, how many users
+// were eligible at the slot's first draw, why the slot stayed below its minimum if it did, and how many
+// other eligible users could have covered it. Pool size and alternatives are empty for cells that were
+// not drawn (dedicated, linked, rotation and fixed cells). taskLabel is the header of the task column.
+func writeAssignReport(info Info, schedule map[string]map[string]string, trace *decisionTrace, taskLabel string, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(assignReportHeader(taskLabel))
+	for _, day := range info.DaysOfWeek {
+		taskSet := make(map[string]Task)
+		for _, task := range info.Tasks {
+			if contains(task.Days, day) || isDedicated(task) || schedule[day][task.Name] != "" {
+				taskSet[task.Name] = task
+			}
+		}
+		names := make([]string, 0, len(taskSet))
+		for name := range taskSet {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			cell := schedule[day][name]
+			decisions := trace.forSlot(day, name)
+			poolSize, alternatives := "", ""
+			if len(decisions) > 0 {
+				eligible, others := 0, 0
+				for _, candidate := range decisions[0].Candidates {
+					if candidate.Reason == "" {
+						eligible++
+						if !cellHas(cell, candidate.Name) {
+							others++
+						}
+					}
+				}
+				poolSize, alternatives = strconv.Itoa(eligible), strconv.Itoa(others)
+			}
+			reason := ""
+			if min, _ := taskRange(taskSet[name]); len(cellAssignees(cell)) < min {
+				reason = unfilledReason(decisions)
+			}
+			writer.Write([]string{day, name, cell, poolSize, reason, alternatives})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
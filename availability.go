@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dayAvailability is how many users are out on one day of the week.
+type dayAvailability struct {
+	Day         string
+	Unavailable []string
+	Total       int
+}
+
+// fraction is the share of users unavailable that day.
+func (d dayAvailability) fraction() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(len(d.Unavailable)) / float64(d.Total)
+}
+
+// availabilityByDay lists, for each day of the week in order, the users who are unavailable.
+func availabilityByDay(info Info) []dayAvailability {
+	days := make([]dayAvailability, 0, len(info.DaysOfWeek))
+	for _, day := range info.DaysOfWeek {
+		entry := dayAvailability{Day: day, Total: len(info.Users)}
+		for _, user := range info.Users {
+			if !isUserAvailable(user, day) {
+				entry.Unavailable = append(entry.Unavailable, user.Name)
+			}
+		}
+		days = append(days, entry)
+	}
+	return days
+}
+
+// understaffedDays returns the days on which more than threshold of all users are unavailable.
+func understaffedDays(info Info, threshold float64) []dayAvailability {
+	var days []dayAvailability
+	for _, entry := range availabilityByDay(info) {
+		if entry.fraction() > threshold {
+			days = append(days, entry)
+		}
+	}
+	return days
+}
+
+// understaffedMessage describes a day whose unavailability is over the threshold.
+func understaffedMessage(entry dayAvailability, threshold float64) string {
+	return fmt.Sprintf("%d of %d users (%.0f%%) are unavailable on %s, over the %.0f%% threshold",
+		len(entry.Unavailable), entry.Total, 100*entry.fraction(), entry.Day, 100*threshold)
+}
+
+// printAvailabilityReport prints who is out each day and flags the days over threshold.
+func printAvailabilityReport(info Info, threshold float64) {
+	fmt.Println("Availability:")
+	for _, entry := range availabilityByDay(info) {
+		out := "nobody out"
+		if len(entry.Unavailable) > 0 {
+			out = "out: " + strings.Join(entry.Unavailable, ", ")
+		}
+		note := ""
+		if entry.fraction() > threshold {
+			note = " (understaffed)"
+		}
+		fmt.Printf("  %s: %d of %d available, %s%s\n", entry.Day, entry.Total-len(entry.Unavailable), entry.Total, out, note)
+	}
+}
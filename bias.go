@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// previousBias returns each user's head start for balancing this week: fraction of the assignments
+// they held in the previous schedule, rounded to the nearest task. Users with no bias are left out.
+func previousBias(info Info, previousSchedule map[string]map[string]string, fraction float64) map[string]int {
+	bias := make(map[string]int)
+	if fraction <= 0 || previousSchedule == nil {
+		return bias
+	}
+	counts := assignmentCounts(previousSchedule)
+	for _, user := range info.Users {
+		if b := int(math.Round(fraction * float64(counts[user.Name]))); b > 0 {
+			bias[user.Name] = b
+		}
+	}
+	return bias
+}
+
+// printBiasReport prints the starting bias applied to each user alongside last week's count.
+func printBiasReport(info Info, previousSchedule map[string]map[string]string, bias map[string]int, fraction float64) {
+	names := make([]string, 0, len(info.Users))
+	for _, user := range info.Users {
+		names = append(names, user.Name)
+	}
+	sort.Strings(names)
+
+	counts := assignmentCounts(previousSchedule)
+	fmt.Printf("Starting bias (%.2f of last week's count):\n", fraction)
+	for _, name := range names {
+		fmt.Printf("  %s: +%d (%d last week)\n", name, bias[name], counts[name])
+	}
+}
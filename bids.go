@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Bid is one ranked preference from a bids file. An empty Day means any day the task runs.
+type Bid struct {
+	Task string `json:"task"`
+	Day  string `json:"day,omitempty"`
+}
+
+// honoredBid records a bid that was granted, with its 1-based rank in the user's list.
+type honoredBid struct {
+	Rank int
+	Task string
+	Day  string
+}
+
+// loadBids reads a bids file mapping each user name to their bids, most preferred first.
+func loadBids(filename string, info Info) (map[string][]Bid, error) {
+	var bids map[string][]Bid
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&bids); err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]bool)
+	for _, user := range info.Users {
+		users[user.Name] = true
+	}
+	tasks := make(map[string]Task)
+	for _, task := range info.Tasks {
+		tasks[task.Name] = task
+	}
+	for name, list := range bids {
+		if !users[name] {
+			return nil, fmt.Errorf("bids for unknown user %q", name)
+		}
+		for _, bid := range list {
+			task, ok := tasks[bid.Task]
+			if !ok {
+				return nil, fmt.Errorf("%s bids on unknown task %q", name, bid.Task)
+			}
+			if bid.Day != "" && !contains(task.Days, bid.Day) {
+				return nil, fmt.Errorf("%s bids on %s on %s, which it does not run", name, bid.Task, bid.Day)
+			}
+		}
+	}
+	return bids, nil
+}
+
+// assignBids grants bids in rounds: in round r every bidder, in random order, gets their r-th choice if
+// the slot still has room and they pass every hard constraint. Nobody is granted more than a fair share
+// of all slots, so a long bid list cannot crowd out everyone else. Dedicated and linked (Late Person
+// Tasks) roles are not biddable. The granted cells are returned on top of fixed, ready to be used as
+// fixed assignments, along with the bids honored per user.
+func assignBids(info Info, previousSchedule map[string]map[string]string, bids map[string][]Bid, fixed map[string]map[string]string) (map[string]map[string]string, map[string][]honoredBid) {
+	schedule := make(map[string]map[string]string)
+	userTaskCount := make(map[string]int)
+	for _, day := range info.DaysOfWeek {
+		schedule[day] = make(map[string]string)
+		for taskName, cell := range fixed[day] {
+			schedule[day][taskName] = cell
+			for _, name := range cellAssignees(cell) {
+				userTaskCount[name]++
+			}
+		}
+	}
+
+	tasks := make(map[string]Task)
+	totalSlots := 0
+	for _, task := range info.Tasks {
+		tasks[task.Name] = task
+		_, max := taskRange(task)
+		totalSlots += max * len(task.Days)
+	}
+	fairShare := 1
+	if len(info.Users) > 0 {
+		fairShare = (totalSlots + len(info.Users) - 1) / len(info.Users)
+	}
+
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	bidders := make([]string, 0, len(bids))
+	rounds := 0
+	for name, list := range bids {
+		bidders = append(bidders, name)
+		if len(list) > rounds {
+			rounds = len(list)
+		}
+	}
+	sort.Strings(bidders)
+
+	honored := make(map[string][]honoredBid)
+	granted := make(map[string]int)
+	for round := 0; round < rounds; round++ {
+		rng.Shuffle(len(bidders), func(i, j int) { bidders[i], bidders[j] = bidders[j], bidders[i] })
+		for _, name := range bidders {
+			if round >= len(bids[name]) || granted[name] >= fairShare {
+				continue
+			}
+			bid := bids[name][round]
+			task := tasks[bid.Task]
+			if isDedicated(task) || task.Name == "Late Person Tasks" {
+				continue
+			}
+			days := task.Days
+			if bid.Day != "" {
+				days = []string{bid.Day}
+			}
+			_, max := taskRange(task)
+			for _, day := range days {
+				if len(cellAssignees(schedule[day][task.Name])) >= max {
+					continue
+				}
+				if exclusionReason(schedule, info, task, day, usersByName[name], userTaskCount, previousSchedule, nil) != "" {
+					continue
+				}
+				schedule[day][task.Name] = addAssignee(schedule[day][task.Name], name)
+				userTaskCount[name]++
+				granted[name]++
+				honored[name] = append(honored[name], honoredBid{Rank: round + 1, Task: task.Name, Day: day})
+				break
+			}
+		}
+	}
+
+	result := make(map[string]map[string]string)
+	for day, cells := range schedule {
+		if len(cells) > 0 {
+			result[day] = cells
+		}
+	}
+	return result, honored
+}
+
+// printBidReport prints the overall satisfaction score and each bidder's best honored preference.
+// A bid honored at rank r scores 1/r, so the maximum is reached when everyone gets every bid.
+func printBidReport(bids map[string][]Bid, honored map[string][]honoredBid) {
+	names := make([]string, 0, len(bids))
+	for name := range bids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	score, maxScore := 0.0, 0.0
+	fmt.Println("Bid results:")
+	for _, name := range names {
+		for rank := range bids[name] {
+			maxScore += 1 / float64(rank+1)
+		}
+		granted := honored[name]
+		for _, bid := range granted {
+			score += 1 / float64(bid.Rank)
+		}
+		if len(granted) == 0 {
+			fmt.Printf("  %s: no bids honored (0 of %d)\n", name, len(bids[name]))
+			continue
+		}
+		best := granted[0]
+		for _, bid := range granted {
+			if bid.Rank < best.Rank {
+				best = bid
+			}
+		}
+		fmt.Printf("  %s: best honored #%d (%s on %s), %d of %d bids honored\n", name, best.Rank, best.Task, best.Day, len(granted), len(bids[name]))
+	}
+	fmt.Printf("Satisfaction score: %.2f of %.2f\n", score, maxScore)
+}
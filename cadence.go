@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cadencePeriods maps a task cadence to how many weeks apart it runs.
+var cadencePeriods = map[string]int{"": 1, "weekly": 1, "biweekly": 2, "monthly": 4}
+
+// runsInWeek reports whether a task is scheduled in the given week index (see rotationWeekIndex), so
+// cadences follow the calendar across separate runs. Cadenced tasks run on their anchor week (default 1)
+// and every period after (and before) it.
+func runsInWeek(task Task, week int) bool {
+	period := cadencePeriods[task.Cadence]
+	if period <= 1 {
+		return true
+	}
+	anchor := task.CadenceAnchor
+	if anchor == 0 {
+		anchor = 1
+	}
+	return ((week-anchor)%period+period)%period == 0
+}
+
+// tasksForWeek returns a copy of info holding only the tasks that run in the given week.
+// Skipped tasks are left out entirely rather than appearing as unfilled rows.
+func tasksForWeek(info Info, week int) Info {
+	weekInfo := info
+	weekInfo.Tasks = nil
+	for _, task := range info.Tasks {
+		if runsInWeek(task, week) {
+			weekInfo.Tasks = append(weekInfo.Tasks, task)
+		}
+	}
+	return weekInfo
+}
+
+// printCadenceReport lists the week indices, from first over the given number of weeks, in which each
+// non-weekly task was scheduled.
+func printCadenceReport(info Info, first, weeks int) {
+	var lines []string
+	for _, task := range info.Tasks {
+		if cadencePeriods[task.Cadence] <= 1 {
+			continue
+		}
+		var scheduled []string
+		for week := first; week < first+weeks; week++ {
+			if runsInWeek(task, week) {
+				scheduled = append(scheduled, strconv.Itoa(week))
+			}
+		}
+		if len(scheduled) == 0 {
+			scheduled = []string{"none"}
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%s): week %s", task.Name, task.Cadence, strings.Join(scheduled, ", ")))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	sort.Strings(lines)
+	fmt.Println("\nCadenced tasks:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestRunsInWeek(t *testing.T) {
+	tests := []struct {
+		task Task
+		week int
+		want bool
+	}{
+		{Task{}, 43, true},
+		{Task{Cadence: "weekly"}, 43, true},
+		{Task{Cadence: "biweekly"}, 43, true},
+		{Task{Cadence: "biweekly"}, 44, false},
+		{Task{Cadence: "biweekly", CadenceAnchor: 42}, 44, true},
+		{Task{Cadence: "monthly", CadenceAnchor: 2}, 42, true},
+		{Task{Cadence: "monthly", CadenceAnchor: 2}, 43, false},
+		{Task{Cadence: "monthly", CadenceAnchor: 10}, 2, true},
+	}
+	for _, tt := range tests {
+		if got := runsInWeek(tt.task, tt.week); got != tt.want {
+			t.Errorf("runsInWeek(%s anchor %d, %d) = %v, want %v", tt.task.Cadence, tt.task.CadenceAnchor, tt.week, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// taskLoad is how many assignments taking task adds to a user's count. EOD Reports brings
+// Late Person Tasks with it, so it costs two.
+func taskLoad(task Task) int {
+	if task.Name == "EOD Reports" {
+		return 2
+	}
+	return 1
+}
+
+// exceedsTaskCap reports whether adding load assignments would take the user past MaxTasks.
+func exceedsTaskCap(user User, userTaskCount map[string]int, load int) bool {
+	return user.MaxTasks > 0 && userTaskCount[user.Name]+load > user.MaxTasks
+}
+
+// capReason returns the exclusion reason for a user whose MaxTasks would be exceeded by task, or "".
+func capReason(user User, task Task, userTaskCount map[string]int) string {
+	if exceedsTaskCap(user, userTaskCount, taskLoad(task)) {
+		return fmt.Sprintf("weekly task cap of %d reached", user.MaxTasks)
+	}
+	return ""
+}
+
+// leastOverflow returns the over-cap users who would exceed their cap by the smallest amount.
+func leastOverflow(users []User, task Task, userTaskCount map[string]int) []User {
+	overflow := func(user User) int {
+		return userTaskCount[user.Name] + taskLoad(task) - user.MaxTasks
+	}
+	var least []User
+	for _, user := range users {
+		switch {
+		case len(least) == 0 || overflow(user) < overflow(least[0]):
+			least = []User{user}
+		case overflow(user) == overflow(least[0]):
+			least = append(least, user)
+		}
+	}
+	return least
+}
+
+// assignmentCounts counts every assignment in the schedule per user.
+func assignmentCounts(schedule map[string]map[string]string) map[string]int {
+	counts := make(map[string]int)
+	for _, cells := range schedule {
+		for _, cell := range cells {
+			for _, name := range cellAssignees(cell) {
+				counts[name]++
+			}
+		}
+	}
+	return counts
+}
+
+// printSoftCapReport lists the users who ended the week over their MaxTasks and by how much.
+func printSoftCapReport(info Info, userTaskCount map[string]int) {
+	var lines []string
+	for _, user := range info.Users {
+		if user.MaxTasks > 0 && userTaskCount[user.Name] > user.MaxTasks {
+			lines = append(lines, fmt.Sprintf("  %s: %d tasks, %d over the cap of %d", user.Name, userTaskCount[user.Name], userTaskCount[user.Name]-user.MaxTasks, user.MaxTasks))
+		}
+	}
+	if len(lines) == 0 {
+		fmt.Println("Nobody exceeded their soft cap.")
+		return
+	}
+	sort.Strings(lines)
+	fmt.Println("Soft cap exceeded:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
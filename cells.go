@@ -0,0 +1,40 @@
+package main
+
+import "strings"
+
+// assigneeSeparator joins the names of everyone assigned to the same day/task cell.
+const assigneeSeparator = ", "
+
+// cellAssignees splits a schedule cell into the names assigned to it.
+func cellAssignees(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	return strings.Split(cell, assigneeSeparator)
+}
+
+// cellHas reports whether name is one of the assignees in a schedule cell.
+func cellHas(cell, name string) bool {
+	return contains(cellAssignees(cell), name)
+}
+
+// addAssignee returns the cell with name appended to its assignees.
+func addAssignee(cell, name string) string {
+	if cell == "" {
+		return name
+	}
+	return cell + assigneeSeparator + name
+}
+
+// taskRange returns how many people a task needs each day. MinCount defaults to 1 and
+// MaxCount defaults to MinCount, so tasks without counts keep the single-assignee behavior.
+func taskRange(task Task) (min, max int) {
+	min, max = task.MinCount, task.MaxCount
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
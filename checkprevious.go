@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// checkPreviousSchedule validates a previous schedule file against the current configuration and
+// returns one line per problem: a file the loader cannot read, header days that do not match
+// days_of_week, task rows that are unknown or repeated, and assignees no longer on the roster.
+func checkPreviousSchedule(filename string, info Info) []string {
+	if _, err := loadPreviousSchedule(filename); err != nil {
+		return []string{fmt.Sprintf("cannot load %s: %v", filename, err)}
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	header := records[0][1:]
+	for _, day := range header {
+		if !contains(info.DaysOfWeek, day) {
+			problems = append(problems, fmt.Sprintf("header: day %q is not in days_of_week", day))
+		}
+	}
+	for _, day := range info.DaysOfWeek {
+		if !contains(header, day) {
+			problems = append(problems, fmt.Sprintf("header: day %q from days_of_week is missing", day))
+		}
+	}
+
+	tasks := make(map[string]bool)
+	for _, task := range info.Tasks {
+		tasks[task.Name] = true
+	}
+	users := make(map[string]bool)
+	for _, user := range info.Users {
+		users[user.Name] = true
+	}
+
+	seen := make(map[string]bool)
+	stale := make(map[string]int)
+	for i, record := range records[1:] {
+		task := record[0]
+		switch {
+		case seen[task]:
+			problems = append(problems, fmt.Sprintf("row %d: task %q appears more than once", i+2, task))
+		case !tasks[task]:
+			problems = append(problems, fmt.Sprintf("row %d: unknown task %q", i+2, task))
+		}
+		seen[task] = true
+		for _, cell := range record[1:] {
+			for _, name := range cellAssignees(cell) {
+				if !users[name] {
+					stale[name]++
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(stale))
+	for name := range stale {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, fmt.Sprintf("stale user %q is assigned %d time(s) but is not in info.json", name, stale[name]))
+	}
+	return problems
+}
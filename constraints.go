@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// softConstraints are the rules the relaxation ladder may give up, keyed by their constraint_priority
+// name: spacing keeps a task off consecutive days, anti-repeat keeps last week's slots from repeating
+// and variety is each user's MaxDistinctTasks.
+var softConstraints = map[string]bool{"spacing": true, "anti-repeat": true, "variety": true}
+
+// validateConstraintPriority checks that constraint_priority names each soft constraint at most once.
+func validateConstraintPriority(priority []string) error {
+	seen := make(map[string]bool)
+	for _, dimension := range priority {
+		if !softConstraints[dimension] {
+			return fmt.Errorf("constraint_priority: unknown constraint %q (want spacing, anti-repeat or variety)", dimension)
+		}
+		if seen[dimension] {
+			return fmt.Errorf("constraint_priority: %q is listed twice", dimension)
+		}
+		seen[dimension] = true
+	}
+	return nil
+}
+
+// relaxationLadder returns the successive sets of constraints to relax when a slot cannot be filled:
+// the last (lowest-priority) entry first, then the last two, and so on up to the whole list.
+func relaxationLadder(priority []string) []map[string]bool {
+	var ladder []map[string]bool
+	for k := 1; k <= len(priority); k++ {
+		relaxed := make(map[string]bool)
+		for _, dimension := range priority[len(priority)-k:] {
+			relaxed[dimension] = true
+		}
+		ladder = append(ladder, relaxed)
+	}
+	return ladder
+}
+
+// spacingReason is the spacing exclusion: the user did this task the previous day this week.
+func spacingReason(schedule map[string]map[string]string, info Info, task Task, day string, user User) string {
+	if previousDay := previousDayOf(info.DaysOfWeek, day); previousDay != "" && cellHas(schedule[previousDay][task.Name], user.Name) {
+		return "assigned the same task on " + previousDay
+	}
+	return ""
+}
+
+// antiRepeatReason is the anti-repeat exclusion: the user held this slot, or this task the day
+// before, in the previous week's schedule.
+func antiRepeatReason(info Info, task Task, day string, user User, previousSchedule map[string]map[string]string) string {
+	if previousSchedule == nil {
+		return ""
+	}
+	if cellHas(previousSchedule[day][task.Name], user.Name) {
+		return "assigned the same slot last week"
+	}
+	if previousDay := previousDayOf(info.DaysOfWeek, day); previousDay != "" && cellHas(previousSchedule[previousDay][task.Name], user.Name) {
+		return "assigned this task on " + previousDay + " last week"
+	}
+	return ""
+}
+
+// varietyReason is the variety exclusion: the user is already spread across MaxDistinctTasks tasks.
+func varietyReason(schedule map[string]map[string]string, user User, task Task) string {
+	if atDistinctTaskCap(schedule, user, task) {
+		return fmt.Sprintf("distinct-task cap of %d reached", user.MaxDistinctTasks)
+	}
+	return ""
+}
+
+// violatedConstraints lists, in priority order, the relaxed constraints user breaks by taking the slot.
+func violatedConstraints(schedule map[string]map[string]string, info Info, task Task, day string, user User, previousSchedule map[string]map[string]string, relaxed map[string]bool) []string {
+	var violated []string
+	for _, dimension := range info.ConstraintPriority {
+		if !relaxed[dimension] {
+			continue
+		}
+		reason := ""
+		switch dimension {
+		case "spacing":
+			reason = spacingReason(schedule, info, task, day, user)
+		case "anti-repeat":
+			reason = antiRepeatReason(info, task, day, user, previousSchedule)
+		case "variety":
+			reason = varietyReason(schedule, user, task)
+		}
+		if reason != "" {
+			violated = append(violated, dimension)
+		}
+	}
+	return violated
+}
+
+// relaxation is one assignment that was only possible by giving up some soft constraints.
+type relaxation struct {
+	Day         string
+	Task        string
+	User        string
+	Constraints []string
+}
+
+// relaxationLog collects the assignments the relaxation ladder made during a week.
+type relaxationLog struct {
+	Relaxations []relaxation
+}
+
+func (l *relaxationLog) record(r relaxation) {
+	l.Relaxations = append(l.Relaxations, r)
+}
+
+// relaxed reports whether name's assignment to task on day gave up constraint. A nil log relaxes nothing.
+func (l *relaxationLog) relaxed(day, task, name, constraint string) bool {
+	if l == nil {
+		return false
+	}
+	for _, r := range l.Relaxations {
+		if r.Day == day && r.Task == task && r.User == name && contains(r.Constraints, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+// relaxedForUser reports whether any of name's assignments gave up constraint.
+func (l *relaxationLog) relaxedForUser(name, constraint string) bool {
+	if l == nil {
+		return false
+	}
+	for _, r := range l.Relaxations {
+		if r.User == name && contains(r.Constraints, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+// printRelaxationReport prints, for each soft constraint in priority order, how many assignments
+// had to relax it and the share of all assignments that still satisfy it, followed by the relaxed slots.
+func printRelaxationReport(info Info, schedule map[string]map[string]string, log *relaxationLog) {
+	total := 0
+	for _, count := range assignmentCounts(schedule) {
+		total += count
+	}
+	relaxedCounts := make(map[string]int)
+	for _, r := range log.Relaxations {
+		for _, constraint := range r.Constraints {
+			relaxedCounts[constraint]++
+		}
+	}
+
+	fmt.Println("Constraint satisfaction:")
+	for _, dimension := range info.ConstraintPriority {
+		share := 100.0
+		if total > 0 {
+			share = 100 * float64(total-relaxedCounts[dimension]) / float64(total)
+		}
+		fmt.Printf("  %s: relaxed for %d assignment(s), %.1f%% of %d satisfied\n", dimension, relaxedCounts[dimension], share, total)
+	}
+	for _, r := range log.Relaxations {
+		fmt.Printf("  %s / %s: %s (relaxed %s)\n", r.Day, r.Task, r.User, strings.Join(r.Constraints, ", "))
+	}
+}
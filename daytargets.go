@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// validateDayTargets checks that day_targets names known days with non-negative targets.
+func validateDayTargets(info Info) error {
+	for day, target := range info.DayTargets {
+		if !contains(info.DaysOfWeek, day) {
+			return fmt.Errorf("day_targets names unknown day %q", day)
+		}
+		if target < 0 {
+			return fmt.Errorf("day_targets: %s target must not be negative", day)
+		}
+	}
+	return nil
+}
+
+// dayAssignments counts every assignment made on day.
+func dayAssignments(schedule map[string]map[string]string, day string) int {
+	count := 0
+	for _, cell := range schedule[day] {
+		count += len(cellAssignees(cell))
+	}
+	return count
+}
+
+// atDayTarget reports whether day already has as many assignments as its day_targets entry allows.
+// Days without a target are never full.
+func atDayTarget(info Info, schedule map[string]map[string]string, day string) bool {
+	target, ok := info.DayTargets[day]
+	return ok && dayAssignments(schedule, day) >= target
+}
+
+// printDayTargetReport prints actual against target assignments for each day with a target and
+// warns about days that required tasks forced over their target.
+func printDayTargetReport(info Info, schedule map[string]map[string]string) {
+	if len(info.DayTargets) == 0 {
+		return
+	}
+	fmt.Println("Assignments per day:")
+	for _, day := range info.DaysOfWeek {
+		actual := dayAssignments(schedule, day)
+		target, ok := info.DayTargets[day]
+		switch {
+		case !ok:
+			fmt.Printf("  %s: %d (no target)\n", day, actual)
+		case actual > target:
+			fmt.Printf("  %s: %d of target %d (over by %d)\n", day, actual, target, actual-target)
+			log.Printf("Warning: required tasks forced %s over its target of %d assignments", day, target)
+		default:
+			fmt.Printf("  %s: %d of target %d\n", day, actual, target)
+		}
+	}
+}
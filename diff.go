@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CellChange is a single day/task cell whose assignee differs between two schedules.
+type CellChange struct {
+	Day  string
+	Task string
+	Old  string
+	New  string
+}
+
+// diffSchedules compares two schedules cell by cell, in day-of-week order and then task name order.
+func diffSchedules(oldSchedule, newSchedule map[string]map[string]string, daysOfWeek []string) []CellChange {
+	days := append([]string{}, daysOfWeek...)
+	for day := range oldSchedule {
+		if !contains(days, day) {
+			days = append(days, day)
+		}
+	}
+
+	var changes []CellChange
+	for _, day := range days {
+		taskSet := make(map[string]bool)
+		for task := range oldSchedule[day] {
+			taskSet[task] = true
+		}
+		for task := range newSchedule[day] {
+			taskSet[task] = true
+		}
+		tasks := make([]string, 0, len(taskSet))
+		for task := range taskSet {
+			tasks = append(tasks, task)
+		}
+		sort.Strings(tasks)
+
+		for _, task := range tasks {
+			oldName := oldSchedule[day][task]
+			newName := newSchedule[day][task]
+			if oldName != newName {
+				changes = append(changes, CellChange{Day: day, Task: task, Old: oldName, New: newName})
+			}
+		}
+	}
+	return changes
+}
+
+// printScheduleDiff prints each changed cell followed by a per-person summary of gained and lost assignments.
+func printScheduleDiff(changes []CellChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+
+	gained := make(map[string]int)
+	lost := make(map[string]int)
+	for _, change := range changes {
+		fmt.Printf("%s / %s: %s -> %s\n", change.Day, change.Task, displayName(change.Old), displayName(change.New))
+		for _, name := range cellAssignees(change.Old) {
+			if !cellHas(change.New, name) {
+				lost[name]++
+			}
+		}
+		for _, name := range cellAssignees(change.New) {
+			if !cellHas(change.Old, name) {
+				gained[name]++
+			}
+		}
+	}
+
+	people := make(map[string]bool)
+	for name := range gained {
+		people[name] = true
+	}
+	for name := range lost {
+		people[name] = true
+	}
+	names := make([]string, 0, len(people))
+	for name := range people {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n%d cell(s) changed. Changes per person:\n", len(changes))
+	for _, name := range names {
+		fmt.Printf("%s: +%d -%d\n", name, gained[name], lost[name])
+	}
+}
+
+// displayName renders an empty assignee as a readable placeholder.
+func displayName(name string) string {
+	if name == "" {
+		return "(unassigned)"
+	}
+	return name
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultWeekendDays are the weekend days used when info.json does not list weekend_days.
+var defaultWeekendDays = []string{"Saturday", "Sunday"}
+
+// digestLines returns one line per user, sorted by name, summarizing their week:
+// "Name: This week: 6 tasks (EOD Reports x2, Front Desk x3, Coverage x1), 1 weekend day."
+// Tasks are listed by name so the lines diff cleanly from week to week.
+func digestLines(info Info, schedule map[string]map[string]string) []string {
+	weekendDays := weekendDaysOf(info)
+
+	names := make([]string, 0, len(info.Users))
+	for _, user := range info.Users {
+		names = append(names, user.Name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		taskCounts := make(map[string]int)
+		total, weekends := 0, 0
+		for _, day := range info.DaysOfWeek {
+			worked := false
+			for task, cell := range schedule[day] {
+				if cellHas(cell, name) {
+					taskCounts[task]++
+					total++
+					worked = true
+				}
+			}
+			if worked && contains(weekendDays, day) {
+				weekends++
+			}
+		}
+
+		tasks := make([]string, 0, len(taskCounts))
+		for task := range taskCounts {
+			tasks = append(tasks, task)
+		}
+		sort.Strings(tasks)
+		parts := make([]string, len(tasks))
+		for i, task := range tasks {
+			parts[i] = fmt.Sprintf("%s x%d", task, taskCounts[task])
+		}
+
+		line := fmt.Sprintf("%s: This week: %s", name, plural(total, "task"))
+		if len(parts) > 0 {
+			line += " (" + strings.Join(parts, ", ") + ")"
+		}
+		lines = append(lines, fmt.Sprintf("%s, %s.", line, plural(weekends, "weekend day")))
+	}
+	return lines
+}
+
+// plural formats a count with its noun, adding "s" unless the count is one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// writeDigest writes the digest lines to filename.
+func writeDigest(info Info, schedule map[string]map[string]string, filename string) error {
+	return os.WriteFile(filename, []byte(strings.Join(digestLines(info, schedule), "\n")+"\n"), 0o644)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// distinctTaskKey maps a task to the key it counts under for distinct-task caps.
+// Late Person Tasks always comes with EOD Reports, so the pair counts as one task.
+func distinctTaskKey(taskName string) string {
+	if taskName == "Late Person Tasks" {
+		return "EOD Reports"
+	}
+	return taskName
+}
+
+// distinctTasks returns the set of distinct tasks the user holds anywhere in the schedule.
+func distinctTasks(schedule map[string]map[string]string, userName string) map[string]bool {
+	tasks := make(map[string]bool)
+	for _, cells := range schedule {
+		for taskName, cell := range cells {
+			if cellHas(cell, userName) {
+				tasks[distinctTaskKey(taskName)] = true
+			}
+		}
+	}
+	return tasks
+}
+
+// atDistinctTaskCap reports whether taking task would push the user past their MaxDistinctTasks.
+func atDistinctTaskCap(schedule map[string]map[string]string, user User, task Task) bool {
+	if user.MaxDistinctTasks <= 0 {
+		return false
+	}
+	done := distinctTasks(schedule, user.Name)
+	return len(done) >= user.MaxDistinctTasks && !done[distinctTaskKey(task.Name)]
+}
+
+// printDistinctTaskReport prints each person's distinct-task count and whether their cap was reached.
+// It prints nothing when no user has a cap.
+func printDistinctTaskReport(info Info, schedule map[string]map[string]string) {
+	capped := false
+	for _, user := range info.Users {
+		if user.MaxDistinctTasks > 0 {
+			capped = true
+		}
+	}
+	if !capped {
+		return
+	}
+
+	users := append([]User{}, info.Users...)
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	fmt.Println("Distinct tasks per person:")
+	for _, user := range users {
+		count := len(distinctTasks(schedule, user.Name))
+		switch {
+		case user.MaxDistinctTasks <= 0:
+			fmt.Printf("  %s: %d\n", user.Name, count)
+		case count >= user.MaxDistinctTasks:
+			fmt.Printf("  %s: %d of %d (cap reached)\n", user.Name, count, user.MaxDistinctTasks)
+		default:
+			fmt.Printf("  %s: %d of %d\n", user.Name, count, user.MaxDistinctTasks)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+)
+
+// messages is the label set used by the markdown and html outputs. A -messages file supplies a
+// translation; any key it leaves out falls back to English.
+type messages struct {
+	Title      string            `json:"title"`
+	Task       string            `json:"task"`       // task column header
+	Unassigned string            `json:"unassigned"` // shown in empty cells on days the task runs
+	Days       map[string]string `json:"days"`       // day name as in info.json -> displayed name
+}
+
+// englishMessages are the built-in labels. Task defaults to -task-column-label.
+func englishMessages(taskLabel string) messages {
+	return messages{Title: "Weekly Schedule", Task: taskLabel, Unassigned: "(unassigned)"}
+}
+
+// loadMessages reads a messages file and fills any missing key from the English labels.
+func loadMessages(filename string, taskLabel string) (messages, error) {
+	english := englishMessages(taskLabel)
+	if filename == "" {
+		return english, nil
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return messages{}, err
+	}
+	defer file.Close()
+
+	var loaded messages
+	if err := json.NewDecoder(file).Decode(&loaded); err != nil {
+		return messages{}, err
+	}
+	if loaded.Title == "" {
+		loaded.Title = english.Title
+	}
+	if loaded.Task == "" {
+		loaded.Task = english.Task
+	}
+	if loaded.Unassigned == "" {
+		loaded.Unassigned = english.Unassigned
+	}
+	return loaded, nil
+}
+
+// day returns the displayed name of a day, or the day itself when it is not translated.
+func (m messages) day(day string) string {
+	if name, ok := m.Days[day]; ok && name != "" {
+		return name
+	}
+	return day
+}
+
+// documentGrid lays the schedule out as the task x day grid of the CSV output, with days translated.
+// Empty cells on days a task runs, or any day of a dedicated task, show the unassigned label; cells
+// on days the task does not run stay blank.
+func documentGrid(schedule map[string]map[string]string, info Info, labels messages) ([]string, [][]string) {
+	header := []string{labels.Task}
+	for _, day := range info.DaysOfWeek {
+		header = append(header, labels.day(day))
+	}
+
+	taskSet := make(map[string]bool)
+	for _, dayTasks := range schedule {
+		for task := range dayTasks {
+			taskSet[task] = true
+		}
+	}
+	tasks := make([]string, 0, len(taskSet))
+	for task := range taskSet {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+
+	tasksByName := make(map[string]Task)
+	for _, task := range info.Tasks {
+		tasksByName[task.Name] = task
+	}
+	rows := make([][]string, 0, len(tasks))
+	for _, task := range tasks {
+		row := []string{task}
+		for _, day := range info.DaysOfWeek {
+			cell := schedule[day][task]
+			if t, ok := tasksByName[task]; cell == "" && ok && (contains(t.Days, day) || isDedicated(t)) {
+				cell = labels.Unassigned
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// scheduleToMarkdown writes the schedule as a Markdown document with a title and a table.
+func scheduleToMarkdown(schedule map[string]map[string]string, info Info, labels messages, filename string) error {
+	header, rows := documentGrid(schedule, info, labels)
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	line := func(cells []string) string {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = escape.Replace(cell)
+		}
+		return "| " + strings.Join(escaped, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", labels.Title)
+	b.WriteString(line(header))
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		b.WriteString(line(row))
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0o644)
+}
+
+// scheduleToHTML writes the schedule as a standalone HTML page with a title and a table.
+func scheduleToHTML(schedule map[string]map[string]string, info Info, labels messages, filename string) error {
+	header, rows := documentGrid(schedule, info, labels)
+	title := html.EscapeString(labels.Title)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<table>\n", title, title)
+	b.WriteString("<tr>")
+	for _, cell := range header {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(cell))
+	}
+	b.WriteString("</tr>\n")
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n</body>\n</html>\n")
+	return os.WriteFile(filename, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocumentGridUnassignedOnlyOnRunningDays(t *testing.T) {
+	info := Info{
+		DaysOfWeek: []string{"Monday", "Tuesday"},
+		Tasks: []Task{
+			{Name: "Desk", Days: []string{"Monday"}, Notes: "same person all week"},
+			{Name: "Mail", Days: []string{"Monday", "Tuesday"}},
+			{Name: "Subs", Days: []string{"Monday"}},
+		},
+	}
+	schedule := map[string]map[string]string{
+		"Monday":  {"Desk": "Ann", "Mail": "Bob", "Subs": "Cat"},
+		"Tuesday": {},
+	}
+	header, rows := documentGrid(schedule, info, englishMessages("Task"))
+	if want := []string{"Task", "Monday", "Tuesday"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	want := [][]string{
+		{"Desk", "Ann", "(unassigned)"},
+		{"Mail", "Bob", "(unassigned)"},
+		{"Subs", "Cat", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// duplicateTaskModes lists the accepted -duplicate-tasks values.
+var duplicateTaskModes = map[string]bool{"error": true, "merge": true}
+
+// duplicateTaskNames returns each task name that appears more than once, in order of first appearance.
+func duplicateTaskNames(tasks []Task) []string {
+	seen := make(map[string]int)
+	var duplicates []string
+	for _, task := range tasks {
+		seen[task.Name]++
+		if seen[task.Name] == 2 {
+			duplicates = append(duplicates, task.Name)
+		}
+	}
+	return duplicates
+}
+
+// resolveDuplicateTasks applies the -duplicate-tasks mode. "error" rejects the configuration;
+// "merge" folds every later task into the first one with the same name, see mergeTasks. An empty
+// mode leaves duplicates in place so -lint can report them.
+func resolveDuplicateTasks(info *Info, mode string) error {
+	duplicates := duplicateTaskNames(info.Tasks)
+	if len(duplicates) == 0 || mode == "" {
+		return nil
+	}
+	if mode == "error" {
+		return fmt.Errorf("duplicate task name %q (use -duplicate-tasks merge to combine them)", duplicates[0])
+	}
+
+	index := make(map[string]int)
+	var merged []Task
+	for _, task := range info.Tasks {
+		if i, ok := index[task.Name]; ok {
+			merged[i] = mergeTasks(merged[i], task)
+			continue
+		}
+		index[task.Name] = len(merged)
+		merged = append(merged, task)
+	}
+	for _, name := range duplicates {
+		log.Printf("Merged duplicate definitions of task %q", name)
+	}
+	info.Tasks = merged
+	return nil
+}
+
+// mergeTasks combines two definitions of the same task. The result runs on the union of their days and
+// requires the union of their trainings; it is locked if either is locked and optional only if both are.
+// day_overrides are combined with the first definition winning a clash. Every other field (notes,
+// times, counts, cadence, rotation) comes from the first definition unless it left the field empty.
+func mergeTasks(first, second Task) Task {
+	merged := first
+	merged.Days = append([]string{}, first.Days...)
+	for _, day := range second.Days {
+		if !contains(merged.Days, day) {
+			merged.Days = append(merged.Days, day)
+		}
+	}
+	merged.RequiredTrainings = append([]string{}, first.RequiredTrainings...)
+	for _, training := range second.RequiredTrainings {
+		if !contains(merged.RequiredTrainings, training) {
+			merged.RequiredTrainings = append(merged.RequiredTrainings, training)
+		}
+	}
+	merged.Locked = first.Locked || second.Locked
+	merged.Optional = first.Optional && second.Optional
+	if len(second.DayOverrides) > 0 {
+		merged.DayOverrides = make(map[string]string)
+		for day, name := range second.DayOverrides {
+			merged.DayOverrides[day] = name
+		}
+		for day, name := range first.DayOverrides {
+			merged.DayOverrides[day] = name
+		}
+	}
+
+	if merged.Notes == "" {
+		merged.Notes = second.Notes
+	}
+	if merged.Start == "" && merged.End == "" {
+		merged.Start, merged.End = second.Start, second.End
+	}
+	if merged.MinCount == 0 && merged.MaxCount == 0 {
+		merged.MinCount, merged.MaxCount = second.MinCount, second.MaxCount
+	}
+	if merged.Cadence == "" {
+		merged.Cadence, merged.CadenceAnchor = second.Cadence, second.CadenceAnchor
+	}
+	if len(merged.RotationOrder) == 0 {
+		merged.RotationOrder = second.RotationOrder
+	}
+	return merged
+}
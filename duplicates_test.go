@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// duplicatedInfo defines "Mail" twice with overlapping days and trainings and clashing details.
+func duplicatedInfo() Info {
+	return Info{
+		DaysOfWeek: []string{"Monday", "Tuesday", "Wednesday"},
+		Users:      []User{{Name: "Ann", Trainings: []string{"post", "van"}}},
+		Tasks: []Task{
+			{Name: "Mail", RequiredTrainings: []string{"post"}, Days: []string{"Monday", "Tuesday"}, Notes: "first", Start: "08:00", End: "09:00", Optional: true},
+			{Name: "Phones", Days: []string{"Monday"}},
+			{Name: "Mail", RequiredTrainings: []string{"van", "post"}, Days: []string{"Tuesday", "Wednesday"}, Notes: "second", Start: "10:00", End: "11:00", Locked: true},
+		},
+	}
+}
+
+func TestResolveDuplicateTasksError(t *testing.T) {
+	info := duplicatedInfo()
+	err := resolveDuplicateTasks(&info, "error")
+	if err == nil {
+		t.Fatal("resolveDuplicateTasks(error) returned nil for a duplicated task")
+	}
+	if !strings.Contains(err.Error(), `"Mail"`) {
+		t.Errorf("error %q does not name the duplicated task", err)
+	}
+	if len(info.Tasks) != 3 {
+		t.Errorf("error mode changed the task list to %d tasks", len(info.Tasks))
+	}
+}
+
+func TestResolveDuplicateTasksMerge(t *testing.T) {
+	info := duplicatedInfo()
+	if err := resolveDuplicateTasks(&info, "merge"); err != nil {
+		t.Fatalf("resolveDuplicateTasks(merge) = %v", err)
+	}
+	if len(info.Tasks) != 2 || info.Tasks[0].Name != "Mail" || info.Tasks[1].Name != "Phones" {
+		t.Fatalf("merged tasks = %v, want Mail then Phones", info.Tasks)
+	}
+	mail := info.Tasks[0]
+	if want := []string{"Monday", "Tuesday", "Wednesday"}; !reflect.DeepEqual(mail.Days, want) {
+		t.Errorf("Days = %v, want %v", mail.Days, want)
+	}
+	if want := []string{"post", "van"}; !reflect.DeepEqual(mail.RequiredTrainings, want) {
+		t.Errorf("RequiredTrainings = %v, want %v", mail.RequiredTrainings, want)
+	}
+	if mail.Notes != "first" || mail.Start != "08:00" || mail.End != "09:00" {
+		t.Errorf("clashing fields = %q %s-%s, want the first definition's", mail.Notes, mail.Start, mail.End)
+	}
+	if !mail.Locked {
+		t.Error("Locked = false, want true when either definition is locked")
+	}
+	if mail.Optional {
+		t.Error("Optional = true, want false unless both definitions are optional")
+	}
+}
+
+func TestMergeTasksFillsEmptyFields(t *testing.T) {
+	first := Task{Name: "Mail", Optional: true, DayOverrides: map[string]string{"Monday": "Ann"}}
+	second := Task{Name: "Mail", Notes: "same person all week", Start: "08:00", End: "09:00", Optional: true,
+		DayOverrides: map[string]string{"Monday": "Bob", "Tuesday": "Bob"}}
+	merged := mergeTasks(first, second)
+	if merged.Notes != "same person all week" || merged.Start != "08:00" || merged.End != "09:00" {
+		t.Errorf("empty fields were not taken from the second definition: %+v", merged)
+	}
+	if !merged.Optional {
+		t.Error("Optional = false, want true when both definitions are optional")
+	}
+	if want := map[string]string{"Monday": "Ann", "Tuesday": "Bob"}; !reflect.DeepEqual(merged.DayOverrides, want) {
+		t.Errorf("DayOverrides = %v, want %v", merged.DayOverrides, want)
+	}
+}
+
+func TestResolveDuplicateTasksLeaveInPlace(t *testing.T) {
+	info := duplicatedInfo()
+	if err := resolveDuplicateTasks(&info, ""); err != nil {
+		t.Fatalf("resolveDuplicateTasks(\"\") = %v", err)
+	}
+	if len(info.Tasks) != 3 {
+		t.Errorf("empty mode changed the task list to %d tasks", len(info.Tasks))
+	}
+}
+
+func TestLintInfoReportsDuplicateTasks(t *testing.T) {
+	findings := lintInfo(duplicatedInfo(), 1)
+	found := false
+	for _, finding := range findings {
+		if finding.Level == "error" && strings.Contains(finding.Message, `"Mail"`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("lintInfo() = %v, want an error naming \"Mail\"", findings)
+	}
+	if !hasLintErrors(findings) {
+		t.Error("hasLintErrors() = false with a duplicate task")
+	}
+
+	info := duplicatedInfo()
+	info.Tasks = info.Tasks[:2]
+	if findings := lintInfo(info, 1); hasLintErrors(findings) {
+		t.Errorf("lintInfo() = %v, want no errors without duplicates", findings)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import "fmt"
+
+// infeasibleSlots lists the required slots the roster cannot cover in principle, whatever the draw:
+// task/day slots with fewer trained and available users than the task's minimum, and dedicated roles
+// nobody trained can hold every day that is not handed to a day_overrides backup. Fairness, rotation
+// and the rest rules are ignored, and optional tasks are skipped.
+func infeasibleSlots(info Info) []string {
+	var slots []string
+	for _, task := range info.Tasks {
+		if task.Optional {
+			continue
+		}
+		if isDedicated(task) {
+			holder := false
+			for _, user := range info.Users {
+				if !userHasTraining(user, task.RequiredTrainings) {
+					continue
+				}
+				available := true
+				for _, day := range info.DaysOfWeek {
+					if _, overridden := task.DayOverrides[day]; !overridden && !isUserAvailable(user, day) {
+						available = false
+						break
+					}
+				}
+				if available {
+					holder = true
+					break
+				}
+			}
+			if !holder {
+				slots = append(slots, fmt.Sprintf("%s: nobody trained is available all week for this dedicated role", task.Name))
+			}
+			continue
+		}
+
+		min, _ := taskRange(task)
+		for _, day := range task.Days {
+			qualified := 0
+			for _, user := range info.Users {
+				if userHasTraining(user, task.RequiredTrainings) && isUserAvailable(user, day) {
+					qualified++
+				}
+			}
+			if qualified < min {
+				slots = append(slots, fmt.Sprintf("%s on %s: %d qualified and available, %d needed", task.Name, day, qualified, min))
+			}
+		}
+	}
+	return slots
+}
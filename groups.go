@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// applyGroupUnavailability merges each group's unavailable days into the DaysUnavailable of its members.
+// It fails if a group lists an unknown user or if unavailability is given for an undefined group.
+func applyGroupUnavailability(info *Info) error {
+	userIndex := make(map[string]int)
+	for i, user := range info.Users {
+		userIndex[user.Name] = i
+	}
+
+	for group, members := range info.Groups {
+		for _, member := range members {
+			if _, ok := userIndex[member]; !ok {
+				return fmt.Errorf("group %q: unknown user %q", group, member)
+			}
+		}
+	}
+
+	for group, days := range info.GroupUnavailable {
+		members, ok := info.Groups[group]
+		if !ok {
+			return fmt.Errorf("group_unavailable: unknown group %q", group)
+		}
+		for _, member := range members {
+			user := &info.Users[userIndex[member]]
+			for _, day := range days {
+				if !contains(user.DaysUnavailable, day) {
+					user.DaysUnavailable = append(user.DaysUnavailable, day)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lintFinding is a configuration issue reported by -lint. Warnings do not stop generation.
+type lintFinding struct {
+	Level   string // "warning" or "error"
+	Message string
+}
+
+func (f lintFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.Level, f.Message)
+}
+
+// lintInfo inspects the configuration for likely data errors without generating a schedule.
+// Days on which more than unavailableThreshold of all users are out are flagged as understaffed.
+func lintInfo(info Info, unavailableThreshold float64) []lintFinding {
+	var findings []lintFinding
+	for _, name := range idleUsers(info) {
+		findings = append(findings, lintFinding{"warning", fmt.Sprintf("user %q is eligible for no task (check trainings and availability)", name)})
+	}
+	for _, name := range duplicateTaskNames(info.Tasks) {
+		findings = append(findings, lintFinding{"error", fmt.Sprintf("task %q is defined more than once (generation fails unless -duplicate-tasks merge)", name)})
+	}
+	for _, entry := range understaffedDays(info, unavailableThreshold) {
+		findings = append(findings, lintFinding{"warning", understaffedMessage(entry, unavailableThreshold)})
+	}
+	return findings
+}
+
+// idleUsers returns the users who are not trained and available for any task on any of its days,
+// so they can never be scheduled with the current configuration.
+func idleUsers(info Info) []string {
+	var idle []string
+	for _, user := range info.Users {
+		eligible := false
+		for _, task := range info.Tasks {
+			if !userHasTraining(user, task.RequiredTrainings) {
+				continue
+			}
+			for _, day := range task.Days {
+				if isUserAvailable(user, day) {
+					eligible = true
+					break
+				}
+			}
+			if eligible {
+				break
+			}
+		}
+		if !eligible {
+			idle = append(idle, user.Name)
+		}
+	}
+	return idle
+}
+
+// hasLintErrors reports whether any finding is at error level.
+func hasLintErrors(findings []lintFinding) bool {
+	for _, finding := range findings {
+		if finding.Level == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// printLintFindings prints each finding on its own line, or a note that the configuration is clean.
+func printLintFindings(findings []lintFinding) {
+	if len(findings) == 0 {
+		fmt.Println("No issues found.")
+		return
+	}
+	lines := make([]string, len(findings))
+	for i, finding := range findings {
+		lines[i] = finding.String()
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
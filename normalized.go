@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeNormalized writes the schedule as a set of keyed CSV tables under dir for loading into a
+// database: assignments.csv (day, task, assignee), users.csv and tasks.csv. Every assignee must
+// appear in users.csv and every assigned task in tasks.csv, or nothing is written.
+func writeNormalized(schedule map[string]map[string]string, info Info, dir string) ([]string, error) {
+	users := make(map[string]bool)
+	for _, user := range info.Users {
+		users[user.Name] = true
+	}
+	tasks := make(map[string]bool)
+	for _, task := range info.Tasks {
+		tasks[task.Name] = true
+	}
+
+	var assignments [][]string
+	for _, day := range info.DaysOfWeek {
+		taskNames := make([]string, 0, len(schedule[day]))
+		for task := range schedule[day] {
+			taskNames = append(taskNames, task)
+		}
+		sort.Strings(taskNames)
+		for _, task := range taskNames {
+			if !tasks[task] {
+				return nil, fmt.Errorf("assignment on %s references task %q missing from tasks.csv", day, task)
+			}
+			for _, name := range cellAssignees(schedule[day][task]) {
+				if !users[name] {
+					return nil, fmt.Errorf("assignment %s / %s references user %q missing from users.csv", day, task, name)
+				}
+				assignments = append(assignments, []string{day, task, name})
+			}
+		}
+	}
+
+	userRows := make([][]string, 0, len(info.Users))
+	for _, user := range info.Users {
+		userRows = append(userRows, []string{user.Name, strings.Join(user.Trainings, ";"), strings.Join(user.DaysUnavailable, ";")})
+	}
+	sort.Slice(userRows, func(i, j int) bool { return userRows[i][0] < userRows[j][0] })
+
+	taskRows := make([][]string, 0, len(info.Tasks))
+	for _, task := range info.Tasks {
+		taskRows = append(taskRows, []string{task.Name, strings.Join(task.RequiredTrainings, ";"), strings.Join(task.Days, ";"), task.Notes})
+	}
+	sort.Slice(taskRows, func(i, j int) bool { return taskRows[i][0] < taskRows[j][0] })
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tables := []struct {
+		name   string
+		header []string
+		rows   [][]string
+	}{
+		{"assignments.csv", []string{"day", "task", "assignee"}, assignments},
+		{"users.csv", []string{"name", "trainings", "days_unavailable"}, userRows},
+		{"tasks.csv", []string{"name", "required_trainings", "days", "notes"}, taskRows},
+	}
+	var files []string
+	for _, table := range tables {
+		filename := filepath.Join(dir, table.name)
+		if err := writeCSVTable(filename, table.header, table.rows); err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// writeCSVTable writes a header and rows to a new CSV file.
+func writeCSVTable(filename string, header []string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// scheduleToLongCSV writes the schedule in long format, one Day,Task,Assignee row per assignee.
+// order selects the primary sort key: "day" groups rows by day, "task" groups them by task.
+// Days are always ordered by daysOfWeek and tasks by name. taskLabel is the header of the task column.
+func scheduleToLongCSV(schedule map[string]map[string]string, daysOfWeek []string, order string, taskLabel string, filename string) error {
+	if order != "day" && order != "task" {
+		return fmt.Errorf("unknown long-format order %q (want day or task)", order)
+	}
+
+	type row struct {
+		dayIndex int
+		day      string
+		task     string
+		assignee string
+	}
+	var rows []row
+	for i, day := range daysOfWeek {
+		for task, cell := range schedule[day] {
+			for _, name := range cellAssignees(cell) {
+				rows = append(rows, row{i, day, task, name})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if order == "task" && a.task != b.task {
+			return a.task < b.task
+		}
+		if a.dayIndex != b.dayIndex {
+			return a.dayIndex < b.dayIndex
+		}
+		if a.task != b.task {
+			return a.task < b.task
+		}
+		return a.assignee < b.assignee
+	})
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Day", taskLabel, "Assignee"})
+	for _, r := range rows {
+		writer.Write([]string{r.day, r.task, r.assignee})
+	}
+	return nil
+}
+
+// outputConfig describes how and where generated schedules are written.
+type outputConfig struct {
+	Format    string    // name of a registered Writer, see writers
+	LongOrder string    // primary sort key for long format
+	TaskLabel string    // header of the task column
+	Split     string    // none, user or day
+	Template  string    // file name template, see renderOutputName
+	Messages  messages  // labels for the markdown, html and pdf documents
+	WeekStart time.Time // first day of week 1 when known, for dated documents
+}
+
+// outputPlaceholders are the fields that may appear in an output file name template.
+var outputPlaceholders = map[string]bool{"user": true, "week": true, "day": true, "format": true}
+
+var placeholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// defaultOutputTemplate returns the file name template used when -output is not given. A single
+// unsplit week keeps the historical weekly_schedule.csv / weekly_schedule_long.csv names; other
+// formats get the extension they were registered with.
+func defaultOutputTemplate(format, split string, weeks int) string {
+	name := "weekly_schedule"
+	if format != "csv" {
+		name += "_" + format
+	}
+	switch split {
+	case "user":
+		name += "_{user}"
+	case "day":
+		name += "_{day}"
+	}
+	if weeks > 1 {
+		name += "_week{week}"
+	}
+	return name + writers[format].Extension // formats writing a directory have no extension
+}
+
+// validateOutputTemplate rejects unknown placeholders and templates that would make several
+// outputs collide on the same file name.
+func validateOutputTemplate(template, split string, weeks int) error {
+	used := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !outputPlaceholders[match[1]] {
+			return fmt.Errorf("unknown placeholder {%s} in output template %q", match[1], template)
+		}
+		used[match[1]] = true
+	}
+	switch {
+	case split == "user" && !used["user"]:
+		return fmt.Errorf("output template %q needs {user} when splitting by user", template)
+	case split == "day" && !used["day"]:
+		return fmt.Errorf("output template %q needs {day} when splitting by day", template)
+	case split != "user" && used["user"]:
+		return fmt.Errorf("output template %q uses {user} but -split is not user", template)
+	case split != "day" && used["day"]:
+		return fmt.Errorf("output template %q uses {day} but -split is not day", template)
+	case weeks > 1 && !used["week"]:
+		return fmt.Errorf("output template %q needs {week} when generating %d weeks", template, weeks)
+	}
+	return nil
+}
+
+// renderOutputName fills the placeholders of a template. Substituted values are sanitized so a
+// user or day name can never introduce path separators or other unsafe characters.
+func renderOutputName(template string, fields map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		return sanitizeFileName(fields[match[1:len(match)-1]])
+	})
+}
+
+// sanitizeFileName replaces everything except letters, digits, '.', '-' and '_' with '_'.
+func sanitizeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// existingScheduleFile returns the file -preserve-fixed and -dry-run read the current schedule back
+// from: the first week's output under config. Only an unsplit task x day CSV can be read back.
+func existingScheduleFile(config outputConfig) (string, error) {
+	if config.Format != "csv" {
+		return "", fmt.Errorf("-format %s output cannot be read back (use -format csv)", config.Format)
+	}
+	if config.Split != "none" {
+		return "", fmt.Errorf("-split %s output cannot be read back as a whole week (use -split none)", config.Split)
+	}
+	return renderOutputName(config.Template, map[string]string{"week": "1", "format": config.Format}), nil
+}
+
+// writeSchedule writes one week's schedule according to config and returns the files written.
+// Splitting by user writes each person's own cells; splitting by day writes one file per day.
+func writeSchedule(schedule map[string]map[string]string, info Info, week int, config outputConfig) ([]string, error) {
+	fields := map[string]string{"week": strconv.Itoa(week), "format": config.Format}
+	if !config.WeekStart.IsZero() {
+		config.WeekStart = config.WeekStart.AddDate(0, 0, 7*(week-1))
+	}
+
+	var files []string
+	write := func(part map[string]map[string]string, daysOfWeek []string) error {
+		filename := renderOutputName(config.Template, fields)
+		if writers[config.Format].Extension == "" {
+			// Directory formats use the rendered name, minus any extension, as the directory
+			filename = strings.TrimSuffix(filename, filepath.Ext(filename))
+		}
+		if dir := filepath.Dir(filename); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+		partInfo := info
+		partInfo.DaysOfWeek = daysOfWeek
+		err := writers[config.Format].New(filename, config).Write(part, partInfo)
+		files = append(files, filename)
+		return err
+	}
+
+	switch config.Split {
+	case "user":
+		names := make([]string, 0, len(info.Users))
+		for _, user := range info.Users {
+			names = append(names, user.Name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fields["user"] = name
+			if err := write(userSchedule(schedule, name), info.DaysOfWeek); err != nil {
+				return files, err
+			}
+		}
+	case "day":
+		for _, day := range info.DaysOfWeek {
+			fields["day"] = day
+			if err := write(map[string]map[string]string{day: schedule[day]}, []string{day}); err != nil {
+				return files, err
+			}
+		}
+	default:
+		if err := write(schedule, info.DaysOfWeek); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// userSchedule returns only the cells assigned to userName, with the user as the sole assignee.
+func userSchedule(schedule map[string]map[string]string, userName string) map[string]map[string]string {
+	own := make(map[string]map[string]string)
+	for day, cells := range schedule {
+		own[day] = make(map[string]string)
+		for task, cell := range cells {
+			if cellHas(cell, userName) {
+				own[day][task] = userName
+			}
+		}
+	}
+	return own
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// validateDayOverrides checks that day_overrides only appear on dedicated tasks and name known days and users.
+func validateDayOverrides(info Info) error {
+	users := make(map[string]bool)
+	for _, user := range info.Users {
+		users[user.Name] = true
+	}
+	for _, task := range info.Tasks {
+		if len(task.DayOverrides) > 0 && !isDedicated(task) {
+			return fmt.Errorf("task %q: day_overrides only apply to \"same person all week\" tasks", task.Name)
+		}
+		for day, name := range task.DayOverrides {
+			if !contains(info.DaysOfWeek, day) {
+				return fmt.Errorf("task %q: day_overrides names unknown day %q", task.Name, day)
+			}
+			if !users[name] {
+				return fmt.Errorf("task %q: day_overrides names unknown user %q", task.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// overrideDays returns the days of a dedicated task that go to their backup instead of the primary
+// holder. An override is only used when the backup is trained and available that day; otherwise the
+// primary keeps the day and a warning is logged. A backup missing from the (possibly tag-filtered)
+// roster never covers a day.
+func overrideDays(info Info, task Task) map[string]string {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	days := make(map[string]string)
+	for _, day := range info.DaysOfWeek {
+		name, ok := task.DayOverrides[day]
+		if !ok {
+			continue
+		}
+		if backup, ok := usersByName[name]; !ok || !userHasTraining(backup, task.RequiredTrainings) || !isUserAvailable(backup, day) {
+			log.Printf("Backup %s cannot cover %s on %s; keeping the primary holder", name, task.Name, day)
+			continue
+		}
+		days[day] = name
+	}
+	return days
+}
+
+// printDayOverrideReport lists, for each dedicated task with day_overrides, which days went to the
+// backup and which stayed with the primary holder.
+func printDayOverrideReport(info Info, schedule map[string]map[string]string) {
+	printedHeader := false
+	for _, task := range info.Tasks {
+		if len(task.DayOverrides) == 0 {
+			continue
+		}
+		if !printedHeader {
+			fmt.Println("Dedicated task overrides:")
+			printedHeader = true
+		}
+		for _, day := range info.DaysOfWeek {
+			backup, ok := task.DayOverrides[day]
+			if !ok {
+				continue
+			}
+			if cell := schedule[day][task.Name]; cell == backup {
+				fmt.Printf("  %s on %s: covered by backup %s\n", task.Name, day, backup)
+			} else {
+				fmt.Printf("  %s on %s: override to %s not used, held by %s\n", task.Name, day, backup, displayName(cell))
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerWriter("pdf", ".pdf", func(filename string, config outputConfig) Writer {
+		return pdfWriter{filename, config.Messages, config.WeekStart}
+	})
+}
+
+// pdfWriter renders the task x day grid onto a single landscape Letter page for printing.
+type pdfWriter struct {
+	filename  string
+	labels    messages
+	weekStart time.Time // zero when the week's dates are unknown
+}
+
+// Page geometry in PDF points (1/72 inch).
+const (
+	pdfPageWidth  = 792.0
+	pdfPageHeight = 612.0
+	pdfMargin     = 36.0
+	pdfMaxFont    = 10.0
+	// pdfCharWidth is a conservative average Helvetica (and Helvetica-Bold) glyph width as a fraction
+	// of the font size.
+	pdfCharWidth = 0.6
+)
+
+func (w pdfWriter) Write(schedule Schedule, info Info) error {
+	header, rows := documentGrid(schedule, info, w.labels)
+	dates := weekDates(w.weekStart, info.DaysOfWeek)
+	for i, day := range info.DaysOfWeek {
+		if date, ok := dates[day]; ok {
+			header[i+1] += date.Format(" (Jan 2)")
+		}
+	}
+
+	// Size every column to its longest entry, then pick the largest font that fits the page both ways
+	widths := make([]float64, len(header))
+	for i, cell := range header {
+		widths[i] = float64(len([]rune(cell)))
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := float64(len([]rune(cell))); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	totalChars := 0.0
+	for _, n := range widths {
+		totalChars += n
+	}
+	const padding = 4.0 // points on each side of a cell's text
+	usableWidth := pdfPageWidth - 2*pdfMargin - 2*padding*float64(len(widths))
+	usableHeight := pdfPageHeight - 2*pdfMargin - 60 // title, subtitle and footer
+	size := pdfMaxFont
+	if fit := usableWidth / (totalChars * pdfCharWidth); fit < size {
+		size = fit
+	}
+	if fit := usableHeight / (float64(len(rows)+1) * 1.6); fit < size {
+		size = fit
+	}
+	rowHeight := size * 1.6
+
+	var content bytes.Buffer
+	text := func(font string, fontSize, x, y float64, s string) {
+		fmt.Fprintf(&content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, fontSize, x, y, pdfEscape(s))
+	}
+
+	y := pdfPageHeight - pdfMargin - 16
+	text("F2", 16, pdfMargin, y, w.labels.Title)
+	if !w.weekStart.IsZero() {
+		y -= 16
+		text("F1", 10, pdfMargin, y, "Week of "+w.weekStart.Format("January 2, 2006"))
+	}
+	y -= 14
+
+	// Grid: one row per task below a bold header row, with ruled lines around every cell
+	top := y
+	for r := 0; r <= len(rows); r++ {
+		cells, font := header, "F2"
+		if r > 0 {
+			cells, font = rows[r-1], "F1"
+		}
+		x := pdfMargin
+		for i, cell := range cells {
+			text(font, size, x+padding, y-rowHeight+size*0.45, cell)
+			x += widths[i]*pdfCharWidth*size + 2*padding
+		}
+		y -= rowHeight
+	}
+	right := pdfMargin
+	for _, n := range widths {
+		right += n*pdfCharWidth*size + 2*padding
+	}
+	content.WriteString("0.5 w\n")
+	for r := 0; r <= len(rows)+1; r++ {
+		lineY := top - float64(r)*rowHeight
+		fmt.Fprintf(&content, "%.2f %.2f m %.2f %.2f l S\n", pdfMargin, lineY, right, lineY)
+	}
+	x := pdfMargin
+	for i := 0; i <= len(widths); i++ {
+		fmt.Fprintf(&content, "%.2f %.2f m %.2f %.2f l S\n", x, top, x, y)
+		if i < len(widths) {
+			x += widths[i]*pdfCharWidth*size + 2*padding
+		}
+	}
+
+	text("F1", 8, pdfMargin, pdfMargin-12, "Generated "+time.Now().Format("2006-01-02 15:04"))
+	return os.WriteFile(w.filename, pdfDocument(content.Bytes()), 0o644)
+}
+
+// weekDates maps each day name to its date in the week starting at start, or returns nothing when
+// start is zero. A day name that is not a weekday (time.Weekday) gets no date.
+func weekDates(start time.Time, daysOfWeek []string) map[string]time.Time {
+	dates := make(map[string]time.Time)
+	if start.IsZero() {
+		return dates
+	}
+	for offset := 0; offset < 7; offset++ {
+		date := start.AddDate(0, 0, offset)
+		if contains(daysOfWeek, date.Weekday().String()) {
+			dates[date.Weekday().String()] = date
+		}
+	}
+	return dates
+}
+
+// pdfEscape makes s safe inside a PDF literal string. Characters outside Latin-1 become '?'.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// pdfDocument wraps a page content stream in a minimal single-page PDF using the built-in Helvetica
+// fonts, so no font needs to be embedded.
+func pdfDocument(content []byte) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
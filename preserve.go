@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// fixedAssignments picks the cells of an existing schedule that -preserve-fixed keeps: every day of a
+// dedicated ("same person all week") task whose holder, and any day_overrides backup, is still trained
+// and available on their days, and each cell of a locked task whose assignee is still trained and
+// available that day.
+// Anything that no longer fits the configuration is left out so it gets recomputed.
+func fixedAssignments(existing map[string]map[string]string, info Info) map[string]map[string]string {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+
+	fixed := make(map[string]map[string]string)
+	if len(info.DaysOfWeek) == 0 {
+		return fixed
+	}
+	keep := func(day, taskName, name string) {
+		if fixed[day] == nil {
+			fixed[day] = make(map[string]string)
+		}
+		fixed[day][taskName] = name
+	}
+
+	for _, task := range info.Tasks {
+		switch {
+		case isDedicated(task):
+			// The holder is whoever has the first day not handed to a backup by day_overrides
+			holder := ""
+			for _, day := range info.DaysOfWeek {
+				if cell := existing[day][task.Name]; cell != task.DayOverrides[day] {
+					holder = cell
+					break
+				}
+			}
+			user, ok := usersByName[holder]
+			if !ok || !userHasTraining(user, task.RequiredTrainings) {
+				continue
+			}
+			valid := true
+			for _, day := range info.DaysOfWeek {
+				cell := existing[day][task.Name]
+				if backup, ok := usersByName[task.DayOverrides[day]]; ok && cell == backup.Name {
+					valid = valid && userHasTraining(backup, task.RequiredTrainings) && isUserAvailable(backup, day)
+				} else if cell != holder || !isUserAvailable(user, day) {
+					valid = false
+				}
+			}
+			if valid {
+				for _, day := range info.DaysOfWeek {
+					keep(day, task.Name, existing[day][task.Name])
+				}
+			}
+		case task.Locked:
+			for _, day := range task.Days {
+				cell := ""
+				for _, name := range cellAssignees(existing[day][task.Name]) {
+					user, ok := usersByName[name]
+					if ok && userHasTraining(user, task.RequiredTrainings) && isUserAvailable(user, day) {
+						cell = addAssignee(cell, name)
+					}
+				}
+				if cell != "" {
+					keep(day, task.Name, cell)
+				}
+			}
+		}
+	}
+	return fixed
+}
+
+// printPreservationReport lists the preserved cells and how many assigned cells were recomputed.
+func printPreservationReport(fixed, schedule map[string]map[string]string, daysOfWeek []string) {
+	preserved, recomputed := 0, 0
+	fmt.Println("Preserved slots:")
+	for _, day := range daysOfWeek {
+		tasks := make([]string, 0, len(schedule[day]))
+		for task := range schedule[day] {
+			tasks = append(tasks, task)
+		}
+		sort.Strings(tasks)
+		for _, task := range tasks {
+			if name, ok := fixed[day][task]; ok {
+				fmt.Printf("  %s / %s: %s\n", day, task, name)
+				preserved++
+			} else {
+				recomputed++
+			}
+		}
+	}
+	fmt.Printf("%d slot(s) preserved, %d slot(s) recomputed.\n", preserved, recomputed)
+}
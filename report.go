@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+// printRangeCoverage reports, for every task that takes more than one person, how many of its
+// MinCount-MaxCount range were filled each day and whether the minimum was met.
+func printRangeCoverage(info Info, schedule map[string]map[string]string) {
+	printedHeader := false
+	for _, task := range info.Tasks {
+		min, max := taskRange(task)
+		if max == 1 {
+			continue
+		}
+		if !printedHeader {
+			fmt.Println("Multi-person task coverage:")
+			printedHeader = true
+		}
+		for _, day := range task.Days {
+			filled := len(cellAssignees(schedule[day][task.Name]))
+			status := ""
+			if filled < min {
+				status = " (below minimum)"
+			}
+			fmt.Printf("  %s on %s: %d filled of %d-%d%s\n", task.Name, day, filled, min, max, status)
+		}
+	}
+}
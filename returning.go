@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// returnWindow is how many days after an absence a user still counts as just back.
+const returnWindow = 2
+
+// returnPolicies lists the accepted -return-policy values.
+var returnPolicies = map[string]bool{"": true, "catch-up": true, "ease-in": true}
+
+// returningDays returns the days on which user is available but was away within the last
+// returnWindow days. An unavailable day earlier in the week counts as an absence, and so does
+// holding no cell at all in the previous week's schedule, which makes the start of the week a return.
+func returningDays(info Info, user User, previousSchedule map[string]map[string]string) map[string]bool {
+	away, lastAway := false, 0
+	if len(previousSchedule) > 0 && !holdsAnyCell(previousSchedule, user.Name) {
+		away, lastAway = true, -1
+	}
+	days := make(map[string]bool)
+	for i, day := range info.DaysOfWeek {
+		if !isUserAvailable(user, day) {
+			away, lastAway = true, i
+			continue
+		}
+		if away && i-lastAway <= returnWindow {
+			days[day] = true
+		}
+	}
+	return days
+}
+
+// holdsAnyCell reports whether name is assigned anywhere in the schedule.
+func holdsAnyCell(schedule map[string]map[string]string, name string) bool {
+	for _, cells := range schedule {
+		for _, cell := range cells {
+			if cellHas(cell, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returnAdjustment is added to a user's task count when balancing a slot on day. Under catch-up a
+// returning user looks one task lighter so they are preferred; under ease-in one task heavier.
+func returnAdjustment(info Info, user User, day string, previousSchedule map[string]map[string]string, policy string) int {
+	if policy == "" || !returningDays(info, user, previousSchedule)[day] {
+		return 0
+	}
+	if policy == "catch-up" {
+		return -1
+	}
+	return 1
+}
+
+// printReturnPolicyReport lists each returning user, the days the policy applied to them and how
+// many assignments they ended up with on those days.
+func printReturnPolicyReport(info Info, schedule, previousSchedule map[string]map[string]string, policy string) {
+	users := append([]User{}, info.Users...)
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+
+	fmt.Printf("Return policy %s:\n", policy)
+	affected := 0
+	for _, user := range users {
+		returning := returningDays(info, user, previousSchedule)
+		if len(returning) == 0 {
+			continue
+		}
+		var days []string
+		held := 0
+		for _, day := range info.DaysOfWeek {
+			if !returning[day] {
+				continue
+			}
+			days = append(days, day)
+			for _, cell := range schedule[day] {
+				if cellHas(cell, user.Name) {
+					held++
+				}
+			}
+		}
+		fmt.Printf("  %s: returning on %s, %d assignment(s) on those days\n", user.Name, strings.Join(days, ", "), held)
+		affected++
+	}
+	if affected == 0 {
+		fmt.Println("  no returning users this week")
+	}
+}
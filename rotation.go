@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// validateRotations checks that rotation_order lists known, trained users and is not combined with a
+// dedicated role.
+func validateRotations(info Info) error {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	for _, task := range info.Tasks {
+		if len(task.RotationOrder) > 0 && isDedicated(task) {
+			return fmt.Errorf("task %q: rotation_order cannot be combined with \"same person all week\"", task.Name)
+		}
+		for _, name := range task.RotationOrder {
+			user, ok := usersByName[name]
+			if !ok {
+				return fmt.Errorf("task %q: rotation_order names unknown user %q", task.Name, name)
+			}
+			if !userHasTraining(user, task.RequiredTrainings) {
+				return fmt.Errorf("task %q: rotation_order member %q lacks the required training", task.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// rotationWeekIndex returns the week index rotations and cadences follow: -week when given, otherwise
+// the ISO week number of -start-date, otherwise the ISO week number of today.
+func rotationWeekIndex(week int, startDate string) (int, error) {
+	if week < 0 {
+		return 0, fmt.Errorf("-week must not be negative")
+	}
+	if week != 0 && startDate != "" {
+		return 0, fmt.Errorf("-week and -start-date are mutually exclusive")
+	}
+	if week != 0 {
+		return week, nil
+	}
+	date := time.Now()
+	if startDate != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", startDate); err != nil {
+			return 0, fmt.Errorf("invalid -start-date %q (want YYYY-MM-DD)", startDate)
+		}
+	}
+	_, isoWeek := date.ISOWeek()
+	return isoWeek, nil
+}
+
+// rotationHolder returns who a rotation task falls to on day: RotationOrder[week % len], or the next
+// person in order when they are not on the (possibly tag-filtered) roster or fail a hard check of
+// exclusionReason: training, availability, an overlapping slot or a task cap. Holding the task on
+// consecutive days is the point of a rotation, so the spacing and anti-repeat rules do not apply. It
+// returns "" when nobody in the rotation can take the day.
+func rotationHolder(
+	schedule map[string]map[string]string,
+	info Info,
+	task Task,
+	day string,
+	week int,
+	userTaskCount map[string]int,
+	previousSchedule map[string]map[string]string) string {
+
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	relaxed := map[string]bool{"spacing": true, "anti-repeat": true}
+	n := len(task.RotationOrder)
+	for i := 0; i < n; i++ {
+		name := task.RotationOrder[(week+i)%n]
+		user, ok := usersByName[name]
+		if ok && exclusionReason(schedule, info, task, day, user, userTaskCount, previousSchedule, relaxed) == "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// printRotationReport prints whose turn each rotation task is and the days the turn was skipped
+// because that person was unavailable or failed another hard check, with who covered instead.
+func printRotationReport(info Info, schedule map[string]map[string]string, week int) {
+	printedHeader := false
+	for _, task := range info.Tasks {
+		if len(task.RotationOrder) == 0 {
+			continue
+		}
+		if !printedHeader {
+			fmt.Printf("Rotations (week index %d):\n", week)
+			printedHeader = true
+		}
+		turn := task.RotationOrder[week%len(task.RotationOrder)]
+		fmt.Printf("  %s: %s's turn\n", task.Name, turn)
+		for _, day := range task.Days {
+			if cell := schedule[day][task.Name]; !cellHas(cell, turn) {
+				fmt.Printf("    %s skipped on %s (%s), covered by %s\n", turn, day, rotationSkipReason(info, turn, day), displayName(cell))
+			}
+		}
+	}
+}
+
+// rotationSkipReason summarizes why name was passed over for their rotation turn on day.
+func rotationSkipReason(info Info, name, day string) string {
+	for _, user := range info.Users {
+		if user.Name != name {
+			continue
+		}
+		if !isUserAvailable(user, day) {
+			return "unavailable"
+		}
+		return "at a task cap or holding an overlapping slot"
+	}
+	return "not on the roster"
+}
@@ -0,0 +1,114 @@
+package main
+
+import "testing"
+
+func TestRotationHolder(t *testing.T) {
+	days := []string{"Monday", "Tuesday"}
+	rotation := Task{Name: "Reports", Days: days, Start: "09:00", End: "10:00", RotationOrder: []string{"Ann", "Bob", "Cat"}}
+	tests := []struct {
+		name     string
+		users    []User
+		schedule map[string]string // Monday cells placed before the rotation
+		counts   map[string]int
+		week     int
+		want     string
+	}{
+		{"turn holder", []User{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cat"}}, nil, nil, 1, "Bob"},
+		{"wraps around", []User{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cat"}}, nil, nil, 5, "Cat"},
+		{"unavailable", []User{{Name: "Ann"}, {Name: "Bob", DaysUnavailable: []string{"Monday"}}, {Name: "Cat"}}, nil, nil, 1, "Cat"},
+		{"filtered off the roster", []User{{Name: "Ann"}, {Name: "Cat"}}, nil, nil, 1, "Cat"},
+		{"at task cap", []User{{Name: "Ann"}, {Name: "Bob", MaxTasks: 1}, {Name: "Cat"}}, nil, map[string]int{"Bob": 1}, 1, "Cat"},
+		{"at distinct task cap", []User{{Name: "Ann"}, {Name: "Bob", MaxDistinctTasks: 1}, {Name: "Cat"}}, map[string]string{"Mail": "Bob"}, nil, 1, "Cat"},
+		{"overlapping slot", []User{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cat"}}, map[string]string{"Phones": "Bob"}, nil, 1, "Cat"},
+		{"nobody eligible", []User{{Name: "Ann", DaysUnavailable: []string{"Monday"}}}, nil, nil, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Info{
+				DaysOfWeek: days,
+				Users:      tt.users,
+				Tasks: []Task{
+					rotation,
+					{Name: "Mail", Days: days},
+					{Name: "Phones", Days: days, Start: "09:30", End: "11:00"},
+				},
+			}
+			schedule := map[string]map[string]string{"Monday": {}, "Tuesday": {}}
+			for task, cell := range tt.schedule {
+				schedule["Monday"][task] = cell
+			}
+			counts := tt.counts
+			if counts == nil {
+				counts = assignmentCounts(schedule)
+			}
+			if got := rotationHolder(schedule, info, rotation, "Monday", tt.week, counts, nil); got != tt.want {
+				t.Errorf("rotationHolder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotationRespectsCapsUnderSelfCheck(t *testing.T) {
+	days := []string{"Monday", "Tuesday", "Wednesday"}
+	info := Info{
+		DaysOfWeek: days,
+		Users:      []User{{Name: "Ann", MaxTasks: 1}, {Name: "Bob"}, {Name: "Cat"}},
+		Tasks:      []Task{{Name: "Reports", Days: days, RotationOrder: []string{"Cat", "Ann"}}},
+	}
+	opts := scheduleOptions{RotationWeek: 1}
+	schedule, userTaskCount := generateWeeklySchedule(info, nil, opts)
+	if violations := selfCheck(info, schedule, userTaskCount, opts); len(violations) > 0 {
+		t.Fatalf("self-check reports %v", violations)
+	}
+	want := map[string]string{"Monday": "Ann", "Tuesday": "Cat", "Wednesday": "Cat"}
+	for day, name := range want {
+		if got := schedule[day]["Reports"]; got != name {
+			t.Errorf("Reports on %s = %q, want %q", day, got, name)
+		}
+	}
+}
+
+func TestRotationWeekIndex(t *testing.T) {
+	tests := []struct {
+		week      int
+		startDate string
+		want      int
+		wantErr   bool
+	}{
+		{week: 7, want: 7},
+		{startDate: "2026-01-05", want: 2},
+		{week: -1, wantErr: true},
+		{week: 3, startDate: "2026-01-05", wantErr: true},
+		{startDate: "05/01/2026", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := rotationWeekIndex(tt.week, tt.startDate)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("rotationWeekIndex(%d, %q) = %d, %v; want %d, error %v", tt.week, tt.startDate, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestExplainWhyNotRotation(t *testing.T) {
+	days := []string{"Monday", "Tuesday"}
+	info := Info{
+		DaysOfWeek: days,
+		Users:      []User{{Name: "Ann"}, {Name: "Bob", DaysUnavailable: []string{"Tuesday"}}, {Name: "Cat"}},
+		Tasks:      []Task{{Name: "Oncall", Days: days, RotationOrder: []string{"Ann", "Bob"}}},
+	}
+	opts := scheduleOptions{RotationWeek: 1, Trace: &decisionTrace{}}
+	schedule, _ := generateWeeklySchedule(info, nil, opts)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Cat:Oncall:Monday", "Oncall follows rotation_order and it was Bob's turn (week index 1)."},
+		{"Cat:Oncall:Tuesday", "Oncall follows rotation_order and it was Bob's turn (week index 1); Bob was skipped on Tuesday (unavailable), so it went to Ann as next in order."},
+	}
+	for _, tt := range tests {
+		got, err := explainWhyNot(tt.query, info, schedule, opts.Trace, opts.RotationWeek)
+		if err != nil || got != tt.want {
+			t.Errorf("explainWhyNot(%q) = %q, %v; want %q", tt.query, got, err, tt.want)
+		}
+	}
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +21,17 @@ type User struct {
 	Name            string   `json:"name"`
 	Trainings       []string `json:"trainings"`
 	DaysUnavailable []string `json:"days_unavailable"`
+	// MaxDistinctTasks caps how many different tasks the user does in a week; 0 means no cap.
+	MaxDistinctTasks int `json:"max_distinct_tasks,omitempty"`
+	// MaxTasks caps the user's assignments per week; 0 means no cap. With -soft-cap it may be
+	// exceeded when nobody under their cap can cover a slot.
+	MaxTasks int `json:"max_tasks,omitempty"`
+	// Trainee marks someone in on-the-job training; with -pair-trainees a trainer joins their slots.
+	Trainee bool `json:"trainee,omitempty"`
+	// TrainerFor lists the tasks this user can supervise trainees on.
+	TrainerFor []string `json:"trainer_for,omitempty"`
+	// Tags are free-form labels such as "senior" or "night-shift" used by -with-tag and -without-tag.
+	Tags []string `json:"tags,omitempty"`
 }
 
 // Task represents a task with required training and days on which it can be performed.
@@ -25,6 +40,22 @@ type Task struct {
 	RequiredTrainings []string `json:"required_trainings"`
 	Days              []string `json:"days"`
 	Notes             string   `json:"notes"`
+	Start             string   `json:"start,omitempty"`          // optional "HH:MM" slot start
+	End               string   `json:"end,omitempty"`            // optional "HH:MM" slot end
+	Locked            bool     `json:"locked,omitempty"`         // keep existing assignments on -preserve-fixed regeneration
+	MinCount          int      `json:"min_count,omitempty"`      // people needed each day, default 1
+	MaxCount          int      `json:"max_count,omitempty"`      // people assigned each day when available, default MinCount
+	Cadence           string   `json:"cadence,omitempty"`        // weekly (default), biweekly or monthly (every 4 weeks)
+	CadenceAnchor     int      `json:"cadence_anchor,omitempty"` // a week index (see -week) a cadenced task runs in, default 1
+
+	// DayOverrides hands individual days of a dedicated task to a backup, e.g. {"Friday": "Sam"}
+	// while the primary holder covers the rest of the week.
+	DayOverrides map[string]string `json:"day_overrides,omitempty"`
+	// Optional tasks are left unassigned on days that have reached their day_targets entry.
+	Optional bool `json:"optional,omitempty"`
+	// RotationOrder turns the task into a fixed weekly rotation: it goes to RotationOrder[week % len],
+	// or the next person in order on days they are unavailable, instead of a fair random draw.
+	RotationOrder []string `json:"rotation_order,omitempty"`
 }
 
 // Info represents the structure of the info.json file.
@@ -33,10 +64,30 @@ type Info struct {
 	Tasks      []Task            `json:"tasks"`
 	Trainings  map[string]string `json:"trainings"`
 	DaysOfWeek []string          `json:"days_of_week"`
+
+	// Groups maps a group name to its member user names; GroupUnavailable lists the days a
+	// whole group is out. Group days are merged into each member's DaysUnavailable on load.
+	Groups           map[string][]string `json:"groups,omitempty"`
+	GroupUnavailable map[string][]string `json:"group_unavailable,omitempty"`
+
+	// WeekendDays names the days counted as weekend days by -digest and -no-consecutive-weekends;
+	// default Saturday and Sunday.
+	WeekendDays []string `json:"weekend_days,omitempty"`
+
+	// ConstraintPriority ranks the soft constraints (spacing, anti-repeat, variety) from most to least
+	// important. When a slot cannot be filled, listed constraints are relaxed lowest priority first;
+	// unlisted ones are never relaxed.
+	ConstraintPriority []string `json:"constraint_priority,omitempty"`
+
+	// DayTargets caps the assignments per day, e.g. {"Friday": 12}. Optional tasks are declined once a
+	// day reaches its target; required tasks are still filled and may push it over.
+	DayTargets map[string]int `json:"day_targets,omitempty"`
 }
 
 // loadInfo loads users, tasks, training requirements, and days of the week from the specified JSON file.
-func loadInfo(filename string) (Info, error) {
+// With strict set, keys that match no field (typically misspellings) are rejected instead of ignored.
+// duplicateTasks is the -duplicate-tasks mode for tasks sharing a name, see resolveDuplicateTasks.
+func loadInfo(filename string, strict bool, duplicateTasks string) (Info, error) {
 	var info Info
 	file, err := os.Open(filename)
 	if err != nil {
@@ -45,8 +96,74 @@ func loadInfo(filename string) (Info, error) {
 	defer file.Close()
 
 	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&info)
-	return info, err
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err = decoder.Decode(&info); err != nil {
+		return info, err
+	}
+	if err = resolveDuplicateTasks(&info, duplicateTasks); err != nil {
+		return info, err
+	}
+	if err = applyGroupUnavailability(&info); err != nil {
+		return info, err
+	}
+	return info, validateInfo(info)
+}
+
+// validateInfo checks the loaded configuration for values the scheduler cannot work with.
+func validateInfo(info Info) error {
+	for _, task := range info.Tasks {
+		if task.Start == "" && task.End == "" {
+			continue
+		}
+		start, err := parseClock(task.Start)
+		if err != nil {
+			return fmt.Errorf("task %q: invalid start: %v", task.Name, err)
+		}
+		end, err := parseClock(task.End)
+		if err != nil {
+			return fmt.Errorf("task %q: invalid end: %v", task.Name, err)
+		}
+		if end <= start {
+			return fmt.Errorf("task %q: end %s is not after start %s", task.Name, task.End, task.Start)
+		}
+	}
+	for _, task := range info.Tasks {
+		if _, ok := cadencePeriods[task.Cadence]; !ok {
+			return fmt.Errorf("task %q: unknown cadence %q (want weekly, biweekly or monthly)", task.Name, task.Cadence)
+		}
+		if task.CadenceAnchor < 0 {
+			return fmt.Errorf("task %q: cadence_anchor must not be negative", task.Name)
+		}
+		if task.MinCount < 0 || task.MaxCount < 0 || (task.MaxCount > 0 && task.MaxCount < task.MinCount) {
+			return fmt.Errorf("task %q: invalid count range %d-%d", task.Name, task.MinCount, task.MaxCount)
+		}
+	}
+	if err := validateConstraintPriority(info.ConstraintPriority); err != nil {
+		return err
+	}
+	if err := validateDayOverrides(info); err != nil {
+		return err
+	}
+	if err := validateDayTargets(info); err != nil {
+		return err
+	}
+	if err := validateRotations(info); err != nil {
+		return err
+	}
+	taskNames := make(map[string]bool)
+	for _, task := range info.Tasks {
+		taskNames[task.Name] = true
+	}
+	for _, user := range info.Users {
+		for _, taskName := range user.TrainerFor {
+			if !taskNames[taskName] {
+				return fmt.Errorf("user %q: trainer_for names unknown task %q", user.Name, taskName)
+			}
+		}
+	}
+	return nil
 }
 
 // userHasTraining checks if a user has all the required trainings for a task.
@@ -73,10 +190,12 @@ func isUserAvailable(user User, day string) bool {
 	return true
 }
 
+// rng drives every random choice the scheduler makes, so a run can be reproduced with -seed.
+var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
 // shuffleUsers shuffles the users slice.
 func shuffleUsers(users []User) {
-	rand.NewSource(time.Now().UnixNano())
-	rand.Shuffle(len(users), func(i, j int) {
+	rng.Shuffle(len(users), func(i, j int) {
 		users[i], users[j] = users[j], users[i]
 	})
 }
@@ -94,6 +213,12 @@ func loadPreviousSchedule(filename string) (map[string]map[string]string, error)
 	if err != nil {
 		return nil, err
 	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", filename)
+	}
+	if len(records[0]) < 2 {
+		return nil, fmt.Errorf("%s has no day columns in its header", filename)
+	}
 
 	previousSchedule := make(map[string]map[string]string)
 	daysOfWeek := records[0][1:]
@@ -118,60 +243,53 @@ type WeightedUser struct {
 
 func assignTask(
 	schedule map[string]map[string]string,
-	users []User,
+	info Info,
 	task Task,
 	day string,
 	userTaskCount map[string]int,
-	previousSchedule map[string]map[string]string) bool {
-
-	// Shuffle the users slice normally
-	rand.Shuffle(len(users), func(i, j int) { users[i], users[j] = users[j], users[i] })
+	previousSchedule map[string]map[string]string,
+	opts scheduleOptions) bool {
 
-	// Determine the previous day correctly
-	days := getSortedKeys(schedule) // Ensure this function returns the days in correct order
-	previousDayIndex := -1
-	for i, d := range days {
-		if d == day {
-			previousDayIndex = i - 1
-			break
-		}
-	}
-	previousDay := ""
-	if previousDayIndex >= 0 {
-		previousDay = days[previousDayIndex]
+	// Record how this slot was decided when a trace is requested
+	decision := slotDecision{Day: day, Task: task.Name}
+	if opts.Trace != nil {
+		defer func() { opts.Trace.record(decision) }()
 	}
 
-	previousUser := ""
-	if previousSchedule != nil && previousDay != "" {
-		if _, exists := previousSchedule[previousDay]; exists {
-			previousUser = previousSchedule[previousDay][task.Name]
-		}
-	}
+	// Shuffle the users slice normally
+	users := info.Users
+	rng.Shuffle(len(users), func(i, j int) { users[i], users[j] = users[j], users[i] })
 
 	// Filter users who meet the criteria
-	var eligibleUsers []User
+	var eligibleUsers, overCapUsers []User
 	for _, user := range users {
-
-		// Skip Sophia 80% of the time
-		if user.Name == "Sophia" && userTaskCount[user.Name] == 8 {
-			continue
+		reason := exclusionReason(schedule, info, task, day, user, userTaskCount, previousSchedule, nil)
+		decision.Candidates = append(decision.Candidates, candidateDecision{Name: user.Name, Load: userTaskCount[user.Name], Reason: reason})
+		if reason == "" {
+			eligibleUsers = append(eligibleUsers, user)
+		} else if opts.SoftCap && reason == capReason(user, task, userTaskCount) {
+			overCapUsers = append(overCapUsers, user)
 		}
+	}
 
-		// Skip if the user was assigned the same task on the previous day in the current schedule
-		if previousDay != "" && schedule[previousDay][task.Name] == user.Name {
-			continue
-		}
+	// With a soft cap, overload someone only when nobody under their cap can take the slot
+	if len(eligibleUsers) == 0 && len(overCapUsers) > 0 {
+		eligibleUsers = leastOverflow(overCapUsers, task, userTaskCount)
+	}
 
-		// Skip if the user was assigned the same task on the same day last week
-		if previousSchedule != nil {
-			if prevUser, exists := previousSchedule[day][task.Name]; exists && prevUser == user.Name {
-				continue
+	// Still nobody: climb the relaxation ladder, giving up the lowest-priority soft constraints first
+	var relaxed map[string]bool
+	if len(eligibleUsers) == 0 {
+		for _, step := range relaxationLadder(info.ConstraintPriority) {
+			for _, user := range users {
+				if exclusionReason(schedule, info, task, day, user, userTaskCount, previousSchedule, step) == "" {
+					eligibleUsers = append(eligibleUsers, user)
+				}
+			}
+			if len(eligibleUsers) > 0 {
+				relaxed = step
+				break
 			}
-		}
-
-		// Ensure the user is not the same as the previous week's user and has the required training and availability
-		if user.Name != previousUser && userHasTraining(user, task.RequiredTrainings) && isUserAvailable(user, day) {
-			eligibleUsers = append(eligibleUsers, user)
 		}
 	}
 
@@ -179,18 +297,30 @@ func assignTask(
 		return false // No suitable user found
 	}
 
+	// Keep people who worked last weekend off this weekend while anyone else can take the slot
+	var forcedWeekend bool
+	if opts.Weekends != nil {
+		eligibleUsers, forcedWeekend = restedForWeekend(info, day, eligibleUsers, previousSchedule)
+	}
+
+	// Balance on the task count, shifted by any carried-over bias and for users just back from an
+	// absence under a return policy
+	load := func(user User) int {
+		return userTaskCount[user.Name] + opts.StartingBias[user.Name] + returnAdjustment(info, user, day, previousSchedule, opts.ReturnPolicy)
+	}
+
 	// Find the minimum task count among eligible users
-	minTaskCount := userTaskCount[eligibleUsers[0].Name]
+	minTaskCount := load(eligibleUsers[0])
 	for _, user := range eligibleUsers {
-		if userTaskCount[user.Name] < minTaskCount {
-			minTaskCount = userTaskCount[user.Name]
+		if load(user) < minTaskCount {
+			minTaskCount = load(user)
 		}
 	}
 
 	// Calculate the range of task counts to consider
 	taskCounts := make([]int, len(eligibleUsers))
 	for i, user := range eligibleUsers {
-		taskCounts[i] = userTaskCount[user.Name]
+		taskCounts[i] = load(user)
 	}
 	sort.Ints(taskCounts)
 	rangeEnd := minTaskCount + int(float64(len(eligibleUsers))*0.2)
@@ -198,7 +328,7 @@ func assignTask(
 	// Filter users who have the minimum task count or within the calculated range
 	var leastLoadedUsers []User
 	for _, user := range eligibleUsers {
-		if userTaskCount[user.Name] <= rangeEnd {
+		if load(user) <= rangeEnd {
 			leastLoadedUsers = append(leastLoadedUsers, user)
 		}
 	}
@@ -206,28 +336,188 @@ func assignTask(
 		return false // No suitable user found
 	}
 
+	for _, user := range leastLoadedUsers {
+		decision.Pool = append(decision.Pool, user.Name)
+	}
+
 	// Randomly select from the least loaded users
-	selectedUser := leastLoadedUsers[rand.Intn(len(leastLoadedUsers))]
+	selectedUser := leastLoadedUsers[rng.Intn(len(leastLoadedUsers))]
+	decision.Winner = selectedUser.Name
+
+	if relaxed != nil && opts.Relaxations != nil {
+		opts.Relaxations.record(relaxation{Day: day, Task: task.Name, User: selectedUser.Name,
+			Constraints: violatedConstraints(schedule, info, task, day, selectedUser, previousSchedule, relaxed)})
+	}
+
+	if forcedWeekend {
+		opts.Weekends.Forced = append(opts.Weekends.Forced, fmt.Sprintf("%s / %s: %s", day, task.Name, selectedUser.Name))
+	}
 
 	// Assign the task to the selected user
-	schedule[day][task.Name] = selectedUser.Name
+	schedule[day][task.Name] = addAssignee(schedule[day][task.Name], selectedUser.Name)
 	userTaskCount[selectedUser.Name]++
 	return true
 }
 
-// Helper function to get sorted keys of a map
-func getSortedKeys(m map[string]map[string]string) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+// exclusionReason returns why a user cannot take task on day, or "" if they are eligible.
+// Soft constraints named in relaxed are not checked.
+func exclusionReason(
+	schedule map[string]map[string]string,
+	info Info,
+	task Task,
+	day string,
+	user User,
+	userTaskCount map[string]int,
+	previousSchedule map[string]map[string]string,
+	relaxed map[string]bool) string {
+
+	// Skip Sophia 80% of the time
+	if user.Name == "Sophia" && userTaskCount[user.Name] == 8 {
+		return "workload cap reached"
+	}
+
+	// Skip if the user already holds this slot
+	if cellHas(schedule[day][task.Name], user.Name) {
+		return "already assigned to this slot"
+	}
+
+	// Skip if the user was assigned the same task on the previous day in the current schedule
+	if reason := spacingReason(schedule, info, task, day, user); reason != "" && !relaxed["spacing"] {
+		return reason
 	}
-	sort.Strings(keys)
-	return keys
+
+	// Skip if the user held this slot, or this task the day before, last week
+	if reason := antiRepeatReason(info, task, day, user, previousSchedule); reason != "" && !relaxed["anti-repeat"] {
+		return reason
+	}
+
+	// Skip if the user is already spread across as many different tasks as they want
+	if reason := varietyReason(schedule, user, task); reason != "" && !relaxed["variety"] {
+		return reason
+	}
+
+	// Skip if the user already holds an overlapping time slot that day
+	if overlapping := overlappingAssignment(schedule, info, task, day, user.Name); overlapping != "" {
+		return "time slot overlaps " + overlapping
+	}
+
+	if !userHasTraining(user, task.RequiredTrainings) {
+		return "missing required training"
+	}
+	if !isUserAvailable(user, day) {
+		return "unavailable on " + day
+	}
+
+	// Checked last so that a cap reason means the user is otherwise eligible
+	return capReason(user, task, userTaskCount)
+}
+
+// fillTask tops a day's cell up to the task's MaxCount people, logging a gap when fewer than MinCount
+// end up assigned. Optional tasks stop once the day reaches its day target. It returns the number of
+// people newly assigned.
+func fillTask(
+	schedule map[string]map[string]string,
+	info Info,
+	task Task,
+	day string,
+	userTaskCount map[string]int,
+	previousSchedule map[string]map[string]string,
+	opts scheduleOptions) int {
+
+	if opts.expired() {
+		return 0 // out of time: leave the slot as it is
+	}
+	min, max := taskRange(task)
+	assigned := 0
+	full := func() bool { return task.Optional && atDayTarget(info, schedule, day) }
+	for len(cellAssignees(schedule[day][task.Name])) < max && !full() && assignTask(schedule, info, task, day, userTaskCount, previousSchedule, opts) {
+		assigned++
+		if opts.PairTrainees {
+			assignees := cellAssignees(schedule[day][task.Name])
+			if newest := assignees[len(assignees)-1]; isTrainee(info, newest) {
+				pairTrainer(schedule, info, task, day, userTaskCount, previousSchedule)
+			}
+		}
+	}
+	if opts.PairTrainees {
+		// Checked once the cell is final: a trainer drawn after the trainee still supervises them
+		for _, name := range unsupervisedTrainees(info, task.Name, schedule[day][task.Name]) {
+			log.Printf("Trainee %s is unsupervised on %s on %s", name, task.Name, day)
+		}
+	}
+	if filled := len(cellAssignees(schedule[day][task.Name])); filled < max && full() {
+		log.Printf("Optional task %s on %s left at %d assigned: %s reached its day target", task.Name, day, filled, day)
+	} else if filled == 0 {
+		log.Printf("No user available for task %s on %s", task.Name, day)
+	} else if filled < min {
+		log.Printf("Only %d of %d required users available for task %s on %s", filled, min, task.Name, day)
+	}
+	return assigned
+}
+
+// previousDayOf returns the day preceding day in the week, or "" if day is the first day.
+func previousDayOf(daysOfWeek []string, day string) string {
+	for i, d := range daysOfWeek {
+		if d == day {
+			if i > 0 {
+				return daysOfWeek[i-1]
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// availableOnPrimaryDays reports whether user can cover every day of a dedicated task that is not
+// handed to a backup in overrides.
+func availableOnPrimaryDays(info Info, user User, overrides map[string]string) bool {
+	for _, day := range info.DaysOfWeek {
+		if _, ok := overrides[day]; !ok && !isUserAvailable(user, day) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDedicated reports whether a task must be held by the same person all week.
+func isDedicated(task Task) bool {
+	return task.Notes == "same person all week"
+}
+
+// scheduleOptions carries optional inputs that adjust how generateWeeklySchedule fills the week.
+type scheduleOptions struct {
+	// Fixed holds day -> task -> user assignments that are placed before anything else is scheduled.
+	Fixed map[string]map[string]string
+	// Trace, when set, records every balancer decision for later explanation.
+	Trace *decisionTrace
+	// SoftCap lets users exceed MaxTasks when nobody under their cap is eligible.
+	SoftCap bool
+	// PairTrainees adds a trainer for the task to every slot a trainee is drawn into.
+	PairTrainees bool
+	// Relaxations, when set, records assignments that relaxed soft constraints (see ConstraintPriority).
+	Relaxations *relaxationLog
+	// Weekends, when set, keeps users who worked a weekend day in previousSchedule off weekend slots
+	// where possible and collects the assignments where it was not.
+	Weekends *weekendLog
+	// RotationWeek is the week index rotation tasks advance by.
+	RotationWeek int
+	// StartingBias is a head start per user, counted when balancing but not against caps, so people
+	// who were busy last week draw fewer tasks this week.
+	StartingBias map[string]int
+	// Ctx, when set, bounds the run; once it is done no further slots are filled.
+	Ctx context.Context
+	// ReturnPolicy is "catch-up" or "ease-in" to favour or spare users just back from an absence.
+	ReturnPolicy string
+}
+
+// expired reports whether the run's deadline has passed.
+func (opts scheduleOptions) expired() bool {
+	return opts.Ctx != nil && opts.Ctx.Err() != nil
 }
 
 // generateWeeklySchedule creates a schedule ensuring tasks are assigned to eligible users with the least tasks,
 // while considering the previous week's schedule to avoid repeating tasks for the same users where possible.
-func generateWeeklySchedule(info Info, previousSchedule map[string]map[string]string) (map[string]map[string]string, map[string]int) {
+func generateWeeklySchedule(info Info, previousSchedule map[string]map[string]string, opts scheduleOptions) (map[string]map[string]string, map[string]int) {
 	schedule := make(map[string]map[string]string)
 	userTaskCount := make(map[string]int)
 	taskAssignments := make(map[string]string)
@@ -236,19 +526,64 @@ func generateWeeklySchedule(info Info, previousSchedule map[string]map[string]st
 		schedule[day] = make(map[string]string)
 	}
 
+	// Place fixed assignments first so everything else is scheduled around them
+	for day, tasks := range opts.Fixed {
+		for taskName, cell := range tasks {
+			schedule[day][taskName] = cell
+			for _, name := range cellAssignees(cell) {
+				userTaskCount[name]++
+			}
+			taskAssignments[taskName] = cell
+		}
+	}
+
+	// Rotation tasks go to whoever's turn it is; days nobody in the rotation can take are drawn normally
 	for _, task := range info.Tasks {
-		if task.Notes == "same person all week" {
+		if len(task.RotationOrder) == 0 {
+			continue
+		}
+		for _, day := range task.Days {
+			if schedule[day][task.Name] != "" {
+				continue
+			}
+			if name := rotationHolder(schedule, info, task, day, opts.RotationWeek, userTaskCount, previousSchedule); name != "" {
+				schedule[day][task.Name] = name
+				userTaskCount[name]++
+			}
+		}
+	}
+
+	for _, task := range info.Tasks {
+		if _, exists := taskAssignments[task.Name]; exists {
+			continue
+		}
+		if isDedicated(task) {
+			// Days handed to a backup are placed first and not counted against the primary holder
+			overrides := overrideDays(info, task)
+			for day, name := range overrides {
+				schedule[day][task.Name] = name
+				userTaskCount[name]++
+			}
+			primaryDays := len(info.DaysOfWeek) - len(overrides)
+
 			shuffleUsers(info.Users)
 			for _, user := range info.Users {
-				if userHasTraining(user, task.RequiredTrainings) {
+				if userHasTraining(user, task.RequiredTrainings) && availableOnPrimaryDays(info, user, overrides) &&
+					!hasOverlapDuringWeek(schedule, info, task, user.Name) &&
+					!atDistinctTaskCap(schedule, user, task) && !exceedsTaskCap(user, userTaskCount, primaryDays) {
 					for _, day := range info.DaysOfWeek {
-						schedule[day][task.Name] = user.Name
+						if _, ok := overrides[day]; !ok {
+							schedule[day][task.Name] = user.Name
+						}
 					}
 					taskAssignments[task.Name] = user.Name
-					userTaskCount[user.Name] += len(info.DaysOfWeek)
+					userTaskCount[user.Name] += primaryDays
 					break
 				}
 			}
+			if _, held := taskAssignments[task.Name]; !held {
+				log.Printf("No user can hold dedicated task %s all week; drawing it day by day", task.Name)
+			}
 		}
 	}
 
@@ -256,30 +591,40 @@ func generateWeeklySchedule(info Info, previousSchedule map[string]map[string]st
 	for _, task := range info.Tasks {
 		if task.Name == "EOD Reports" {
 			for _, day := range task.Days {
-				assigned := assignTask(schedule, info.Users, task, day, userTaskCount, previousSchedule)
-				if assigned {
-					schedule[day]["Late Person Tasks"] = schedule[day][task.Name]
-					userTaskCount[schedule[day][task.Name]]++
-				} else {
-					log.Printf("No user available for task %s on %s", task.Name, day)
+				fillTask(schedule, info, task, day, userTaskCount, previousSchedule, opts)
+				if cell, exists := schedule[day][task.Name]; exists {
+					if _, exists := schedule[day]["Late Person Tasks"]; !exists {
+						schedule[day]["Late Person Tasks"] = cell
+						for _, name := range cellAssignees(cell) {
+							userTaskCount[name]++
+						}
+					}
 				}
 			}
 		}
 	}
-	// Assign remaining tasks
+	// Assign remaining tasks, required ones first so optional tasks only use what the day targets leave
+	remaining := make([]Task, 0, len(info.Tasks))
 	for _, task := range info.Tasks {
+		if !task.Optional {
+			remaining = append(remaining, task)
+		}
+	}
+	for _, task := range info.Tasks {
+		if task.Optional {
+			remaining = append(remaining, task)
+		}
+	}
+	for _, task := range remaining {
 		// Check if the task has already been assigned
-		if _, exists := taskAssignments[task.Name]; exists {
+		if _, exists := taskAssignments[task.Name]; exists && isDedicated(task) {
 			continue // Skip this task as it's already been handled
 		}
 		for _, day := range task.Days {
-			if _, exists := schedule[day][task.Name]; exists {
+			if _, max := taskRange(task); len(cellAssignees(schedule[day][task.Name])) >= max {
 				continue // Skip this task as it's already been handled
 			}
-			assigned := assignTask(schedule, info.Users, task, day, userTaskCount, previousSchedule)
-			if !assigned {
-				log.Printf("No user available for task %s on %s", task.Name, day)
-			} else {
+			if fillTask(schedule, info, task, day, userTaskCount, previousSchedule, opts) > 0 {
 				// If the task is successfully assigned, mark it as handled
 				taskAssignments[task.Name] = schedule[day][task.Name]
 			}
@@ -297,7 +642,8 @@ func generateWeeklySchedule(info Info, previousSchedule map[string]map[string]st
 }
 
 // scheduleToCSV writes the schedule to a CSV file, sorting the rows by the normal order of the days of the week.
-func scheduleToCSV(schedule map[string]map[string]string, daysOfWeek []string, filename string) error {
+// taskLabel is the header of the first column.
+func scheduleToCSV(schedule map[string]map[string]string, daysOfWeek []string, taskLabel string, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -307,7 +653,7 @@ func scheduleToCSV(schedule map[string]map[string]string, daysOfWeek []string, f
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	header := append([]string{"Task"}, daysOfWeek...)
+	header := append([]string{taskLabel}, daysOfWeek...)
 	writer.Write(header)
 
 	taskSet := make(map[string]bool)
@@ -339,7 +685,53 @@ func scheduleToCSV(schedule map[string]map[string]string, daysOfWeek []string, f
 	return nil
 }
 
+// exitTimeLimited is the exit status when -deadline cut the run short.
+const exitTimeLimited = 3
+
+var (
+	strictFlag    = flag.Bool("strict", false, "enable all safety checks (implies -selfcheck)")
+	selfCheckFlag = flag.Bool("selfcheck", false, "re-validate the generated schedule and abort on internal errors")
+	dryRunFlag    = flag.Bool("dry-run", false, "print a cell-level diff against the existing output file instead of writing it (csv format only)")
+	formatFlag    = flag.String("format", "csv", "output format: csv (task x day grid), long (one Day,Task,Assignee row per assignee), normalized (assignments/users/tasks tables in a directory), markdown, html or pdf (titled task x day documents)")
+	longOrderFlag = flag.String("long-order", "day", "primary sort key for -format long: day or task")
+	checkPrevFlag = flag.Bool("check-previous", false, "validate previous_weekly_schedule.csv against info.json and exit without generating")
+	preserveFlag  = flag.Bool("preserve-fixed", false, "keep dedicated and locked assignments from the existing output file and recompute the rest (csv format only)")
+	taskLabelFlag = flag.String("task-column-label", "Task", "header label for the task column in all outputs")
+	lintFlag      = flag.Bool("lint", false, "check info.json for likely data errors and exit without generating (status 1 on any error)")
+	bidsFlag      = flag.String("bids", "", "JSON file of ranked task/day preferences per user to honor before fair assignment")
+	pairFlag      = flag.Bool("pair-trainees", false, "co-assign a trainer for the task whenever a trainee is scheduled")
+	softCapFlag   = flag.Bool("soft-cap", false, "treat users' max_tasks as a soft cap that may be exceeded to fill a slot")
+	whyNotFlag    = flag.String("why-not", "", "explain why NAME:Task:Day did not go to NAME")
+	weeksFlag     = flag.Int("weeks", 1, "number of consecutive weeks to generate; -preserve-fixed, -bids, -why-not and -dry-run apply to the first week")
+	splitFlag     = flag.String("split", "none", "write one file per user or per day: none, user or day")
+	seedFlag      = flag.Int64("seed", 0, "random seed for a reproducible schedule (0 picks one from the clock)")
+	sweepFlag     = flag.String("seed-sweep-report", "", "generate -sweep-runs schedules with consecutive seeds and write assignment frequencies and fairness statistics to this .json file, or to this .csv file plus a _summary.csv")
+	sweepRunsFlag = flag.Int("sweep-runs", 20, "number of seeds to run for -seed-sweep-report")
+	returnFlag    = flag.String("return-policy", "", "weight users just back from an absence: catch-up prefers them, ease-in spares them")
+	digestFlag    = flag.String("digest", "", "also write a one-line-per-person weekly digest to this file ({week} placeholder required with -weeks)")
+	thresholdFlag = flag.Float64("unavailable-threshold", 0.5, "warn when more than this fraction of users is unavailable on a day")
+	availFlag     = flag.Bool("availability", false, "print who is unavailable each day before generating")
+	altFlag       = flag.String("alternatives", "", "write up to three fallback candidates per drawn slot to this CSV file ({week} placeholder required with -weeks)")
+	withTagFlag   = flag.String("with-tag", "", "schedule only users carrying all of these comma-separated tags")
+	withoutFlag   = flag.String("without-tag", "", "leave out users carrying any of these comma-separated tags")
+	absenceFlag   = flag.String("simulate-absence", "", "compare the week with and without NAME out on the given comma-separated days, then exit")
+	messagesFlag  = flag.String("messages", "", "JSON file of localized title, task, unassigned and days labels for -format markdown and html")
+	strictJSON    = flag.Bool("strict-json", false, "reject unknown (for example misspelled) fields in info.json")
+	weekFlag      = flag.Int("week", 0, "week index that rotation_order tasks and task cadences follow (default: ISO week of -start-date or today)")
+	startDateFlag = flag.String("start-date", "", "first day (YYYY-MM-DD) of the week being scheduled; its ISO week drives rotations")
+	feasibleFlag  = flag.Bool("feasibility", false, "list required slots no qualified, available user can cover and exit (status 1 if any)")
+	stateFlag     = flag.String("state", "", "JSON file of cumulative counts, fairness debt and rotation position per week, loaded at start and updated after writing; regenerating a week replaces its record (weeks cut short by -deadline are not recorded)")
+	assignRepFlag = flag.String("assign-report", "", "write a per-slot CSV of assignee, pool size, unfilled reason and alternatives ({week} placeholder required with -weeks)")
+	weekendsFlag  = flag.Bool("no-consecutive-weekends", false, "keep anyone who worked last weekend off weekend slots when someone else can cover")
+	dupTasksFlag  = flag.String("duplicate-tasks", "error", "what to do with tasks sharing a name in info.json: error, or merge them into the first")
+	deadlineFlag  = flag.Duration("deadline", 0, "stop after this long (e.g. 30s), write the best schedule so far and exit with status 3")
+	carryBiasFlag = flag.Float64("carry-previous-bias", 0, "start each user's balancing load at this fraction (0-1) of their previous-week count")
+	outputFlag    = flag.String("output", "", "output file name template with {user}, {week}, {day} and {format} placeholders (default depends on -format, -split and -weeks)")
+)
+
 func main() {
+	flag.Parse()
+
 	asciiArt := `
          _         _     _
  ___ ___| |_ ___ _| |_ _| |___ ___
@@ -348,6 +740,87 @@ func main() {
                                    `
 	fmt.Println(asciiArt)
 
+	if _, ok := writers[*formatFlag]; !ok {
+		log.Fatalf("Unknown -format %q (want one of %s)", *formatFlag, writerNames())
+	}
+	if *longOrderFlag != "day" && *longOrderFlag != "task" {
+		log.Fatalf("Unknown -long-order %q (want day or task)", *longOrderFlag)
+	}
+	if *splitFlag != "none" && *splitFlag != "user" && *splitFlag != "day" {
+		log.Fatalf("Unknown -split %q (want none, user or day)", *splitFlag)
+	}
+	if *carryBiasFlag < 0 || *carryBiasFlag > 1 {
+		log.Fatalf("-carry-previous-bias must be between 0 and 1")
+	}
+	if !duplicateTaskModes[*dupTasksFlag] {
+		log.Fatalf("Unknown -duplicate-tasks %q (want error or merge)", *dupTasksFlag)
+	}
+	if !returnPolicies[*returnFlag] {
+		log.Fatalf("Unknown -return-policy %q (want catch-up or ease-in)", *returnFlag)
+	}
+	if *seedFlag != 0 {
+		rng = rand.New(rand.NewSource(*seedFlag))
+	}
+	if *thresholdFlag < 0 || *thresholdFlag > 1 {
+		log.Fatalf("-unavailable-threshold must be between 0 and 1")
+	}
+	if *weeksFlag < 1 {
+		log.Fatalf("-weeks must be at least 1")
+	}
+	weekBase, err := rotationWeekIndex(*weekFlag, *startDateFlag)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	ctx := context.Background()
+	if *deadlineFlag > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *deadlineFlag)
+		defer cancel()
+	}
+	var weekStart time.Time
+	if *startDateFlag != "" {
+		weekStart, _ = time.Parse("2006-01-02", *startDateFlag) // already validated by rotationWeekIndex
+	}
+	stateStart := weekStart // the date -state keys weeks by, like rotationWeekIndex
+	if stateStart.IsZero() {
+		stateStart = time.Now()
+	}
+	output := outputConfig{
+		Format:    *formatFlag,
+		LongOrder: *longOrderFlag,
+		TaskLabel: *taskLabelFlag,
+		Split:     *splitFlag,
+		Template:  *outputFlag,
+		WeekStart: weekStart,
+	}
+	if output.Template == "" {
+		output.Template = defaultOutputTemplate(output.Format, output.Split, *weeksFlag)
+	}
+	if err := validateOutputTemplate(output.Template, output.Split, *weeksFlag); err != nil {
+		log.Fatalf("Invalid -output: %v", err)
+	}
+	if *digestFlag != "" {
+		if err := validateOutputTemplate(*digestFlag, "none", *weeksFlag); err != nil {
+			log.Fatalf("Invalid -digest: %v", err)
+		}
+	}
+	if *altFlag != "" {
+		if err := validateOutputTemplate(*altFlag, "none", *weeksFlag); err != nil {
+			log.Fatalf("Invalid -alternatives: %v", err)
+		}
+	}
+	if *assignRepFlag != "" {
+		if err := validateOutputTemplate(*assignRepFlag, "none", *weeksFlag); err != nil {
+			log.Fatalf("Invalid -assign-report: %v", err)
+		}
+	}
+	var existingFile string
+	if *preserveFlag || *dryRunFlag {
+		if existingFile, err = existingScheduleFile(output); err != nil {
+			log.Fatalf("-preserve-fixed and -dry-run need the existing schedule: %v", err)
+		}
+	}
+
 	exePath, err := os.Executable()
 	if err != nil {
 		log.Fatalf("Error getting executable path: %v", err)
@@ -359,10 +832,73 @@ func main() {
 		log.Fatalf("Error changing working directory: %v", err)
 	}
 
-	info, err := loadInfo("info.json")
+	duplicateMode := *dupTasksFlag
+	if *lintFlag {
+		duplicateMode = "" // leave duplicates in place so lint reports them
+	}
+	info, err := loadInfo("info.json", *strictJSON, duplicateMode)
 	if err != nil {
 		log.Fatalf("Error loading info.json: %v", err)
 	}
+	output.Messages, err = loadMessages(*messagesFlag, *taskLabelFlag)
+	if err != nil {
+		log.Fatalf("Error loading -messages: %v", err)
+	}
+	var state *State
+	if *stateFlag != "" {
+		if state, err = loadState(*stateFlag); err != nil {
+			log.Fatalf("Error loading -state: %v", err)
+		}
+	}
+
+	if *lintFlag {
+		findings := lintInfo(info, *thresholdFlag)
+		printLintFindings(findings)
+		if hasLintErrors(findings) {
+			os.Exit(1)
+		}
+		return
+	}
+	if *checkPrevFlag {
+		problems := checkPreviousSchedule("previous_weekly_schedule.csv", info)
+		if len(problems) == 0 {
+			fmt.Println("previous_weekly_schedule.csv is consistent with info.json.")
+			return
+		}
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		os.Exit(1)
+	}
+	roster := info // before any tag filter, for validating inputs that name users
+	if *withTagFlag != "" || *withoutFlag != "" {
+		info = filterUsersByTag(info, parseTagList(*withTagFlag), parseTagList(*withoutFlag))
+		fmt.Printf("Tag filter kept %d user(s).\n", len(info.Users))
+		for _, slot := range infeasibleSlots(info) {
+			log.Printf("Warning: after the tag filter, %s", slot)
+		}
+	}
+	if *feasibleFlag {
+		slots := infeasibleSlots(info)
+		if len(slots) == 0 {
+			fmt.Println("Every required slot can be covered by at least one qualified, available user.")
+			return
+		}
+		fmt.Printf("%d infeasible slot(s):\n", len(slots))
+		for _, slot := range slots {
+			fmt.Printf("  %s\n", slot)
+		}
+		os.Exit(1)
+	}
+	for _, name := range idleUsers(info) {
+		log.Printf("Warning: %s is eligible for no task and will not be scheduled", name)
+	}
+	for _, entry := range understaffedDays(info, *thresholdFlag) {
+		log.Printf("Warning: %s", understaffedMessage(entry, *thresholdFlag))
+	}
+	if *availFlag {
+		printAvailabilityReport(info, *thresholdFlag)
+	}
 
 	var previousSchedule map[string]map[string]string
 	if _, err := os.Stat("previous_weekly_schedule.csv"); err == nil {
@@ -372,11 +908,206 @@ func main() {
 		}
 	}
 
-	schedule, _ := generateWeeklySchedule(info, previousSchedule)
+	opts := scheduleOptions{SoftCap: *softCapFlag, PairTrainees: *pairFlag, ReturnPolicy: *returnFlag, RotationWeek: weekBase, Ctx: ctx}
+	opts.StartingBias = previousBias(info, previousSchedule, *carryBiasFlag)
 
-	err = scheduleToCSV(schedule, info.DaysOfWeek, "weekly_schedule.csv")
-	if err != nil {
-		log.Fatalf("Error saving schedule: %v", err)
+	if *sweepFlag != "" {
+		firstSeed := *seedFlag
+		if firstSeed == 0 {
+			firstSeed = 1
+		}
+		report := runSeedSweep(tasksForWeek(info, weekBase), previousSchedule, opts, firstSeed, *sweepRunsFlag)
+		files, err := writeSeedSweepReport(report, *sweepFlag)
+		if err != nil {
+			log.Fatalf("Error writing seed sweep report: %v", err)
+		}
+		fmt.Printf("\nSeed sweep complete! Check %s.\n", strings.Join(files, " and "))
+		if opts.expired() {
+			log.Printf("Deadline of %s reached: the sweep covers %d of %d runs", *deadlineFlag, report.Runs, *sweepRunsFlag)
+			os.Exit(exitTimeLimited)
+		}
+		return
+	}
+
+	if *absenceFlag != "" {
+		name, days, err := parseAbsence(*absenceFlag, info)
+		if err != nil {
+			log.Fatalf("Invalid -simulate-absence: %v", err)
+		}
+		seed := *seedFlag
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		simulateAbsence(tasksForWeek(info, weekBase), previousSchedule, opts, seed, name, days)
+		return
+	}
+
+	var preserved map[string]map[string]string
+	if *preserveFlag {
+		existing, err := loadPreviousSchedule(existingFile)
+		if err != nil {
+			log.Fatalf("Error loading existing schedule for -preserve-fixed: %v", err)
+		}
+		preserved = fixedAssignments(existing, tasksForWeek(info, weekBase))
+		opts.Fixed = preserved
+	}
+
+	var bids map[string][]Bid
+	var honored map[string][]honoredBid
+	if *bidsFlag != "" {
+		bids, err = loadBids(*bidsFlag, roster)
+		if err != nil {
+			log.Fatalf("Error loading bids: %v", err)
+		}
+		for name := range bids {
+			if !rosterHas(info, name) {
+				delete(bids, name) // filtered out by -with-tag/-without-tag
+			}
+		}
+		opts.Fixed, honored = assignBids(tasksForWeek(info, weekBase), previousSchedule, bids, opts.Fixed)
+	}
+
+	if *whyNotFlag != "" {
+		opts.Trace = &decisionTrace{}
+	}
+
+	var written []string
+	timeLimited := false
+	for week := 1; week <= *weeksFlag && !timeLimited; week++ {
+		if week > 1 {
+			// Later weeks rotate against the week just generated and take no fixed inputs
+			opts = scheduleOptions{SoftCap: *softCapFlag, PairTrainees: *pairFlag, ReturnPolicy: *returnFlag, RotationWeek: weekBase, Ctx: ctx}
+			fmt.Printf("\n== Week %d ==\n", week)
+		}
+		opts.RotationWeek = weekBase + week - 1 // rotations and cadences follow the calendar week
+		opts.StartingBias = previousBias(info, previousSchedule, *carryBiasFlag)
+		if *weekendsFlag {
+			opts.Weekends = &weekendLog{}
+		}
+		if len(info.ConstraintPriority) > 0 {
+			opts.Relaxations = &relaxationLog{}
+		}
+		if (*altFlag != "" || *assignRepFlag != "") && opts.Trace == nil {
+			opts.Trace = &decisionTrace{}
+		}
+
+		weekInfo := tasksForWeek(info, opts.RotationWeek)
+		schedule, userTaskCount := generateWeeklySchedule(weekInfo, previousSchedule, opts)
+		if opts.expired() {
+			// Keep what was filled in time; later weeks are not attempted
+			log.Printf("Deadline of %s reached during week %d: writing the schedule as filled so far", *deadlineFlag, week)
+			timeLimited = true
+		}
+
+		if week == 1 {
+			if *preserveFlag {
+				printPreservationReport(preserved, schedule, weekInfo.DaysOfWeek)
+			}
+			if bids != nil {
+				printBidReport(bids, honored)
+			}
+			if *whyNotFlag != "" {
+				answer, err := explainWhyNot(*whyNotFlag, weekInfo, schedule, opts.Trace, opts.RotationWeek)
+				if err != nil {
+					log.Fatalf("Error answering -why-not: %v", err)
+				}
+				fmt.Println(answer)
+			}
+		}
+		printRangeCoverage(weekInfo, schedule)
+		printDayOverrideReport(weekInfo, schedule)
+		printDayTargetReport(weekInfo, schedule)
+		printRotationReport(weekInfo, schedule, opts.RotationWeek)
+		if opts.Weekends != nil {
+			printWeekendReport(opts.Weekends)
+		}
+		printDistinctTaskReport(weekInfo, schedule)
+		if *pairFlag {
+			printTraineeReport(weekInfo, schedule)
+		}
+		if *softCapFlag {
+			printSoftCapReport(weekInfo, userTaskCount)
+		}
+		if opts.Relaxations != nil {
+			printRelaxationReport(weekInfo, schedule, opts.Relaxations)
+		}
+		if *carryBiasFlag > 0 && previousSchedule != nil {
+			printBiasReport(weekInfo, previousSchedule, opts.StartingBias, *carryBiasFlag)
+		}
+		if *returnFlag != "" {
+			printReturnPolicyReport(weekInfo, schedule, previousSchedule, *returnFlag)
+		}
+
+		if !*selfCheckFlag && !*strictFlag {
+			// Always worth a warning; -selfcheck and -strict turn these into a failed run below
+			for _, v := range duplicateAssignments(weekInfo, schedule, userTaskCount) {
+				log.Printf("Warning: %s", v)
+			}
+		}
+		if *selfCheckFlag || *strictFlag {
+			if violations := selfCheck(weekInfo, schedule, userTaskCount, opts); len(violations) > 0 {
+				for _, v := range violations {
+					log.Printf("self-check: %s", v)
+				}
+				log.Fatalf("internal error: generated schedule failed self-check with %d violation(s)", len(violations))
+			}
+		}
+
+		if *dryRunFlag {
+			existing := make(map[string]map[string]string)
+			if _, err := os.Stat(existingFile); err == nil {
+				existing, err = loadPreviousSchedule(existingFile)
+				if err != nil {
+					log.Fatalf("Error loading existing schedule: %v", err)
+				}
+			}
+			printScheduleDiff(diffSchedules(existing, schedule, weekInfo.DaysOfWeek))
+			fmt.Printf("\nDry run: %s was not modified.\n", existingFile)
+			return
+		}
+
+		files, err := writeSchedule(schedule, weekInfo, week, output)
+		written = append(written, files...)
+		if err != nil {
+			log.Fatalf("Error saving schedule: %v", err)
+		}
+		if *digestFlag != "" {
+			digestFile := renderOutputName(*digestFlag, map[string]string{"week": strconv.Itoa(week), "format": output.Format})
+			if err := writeDigest(weekInfo, schedule, digestFile); err != nil {
+				log.Fatalf("Error writing digest: %v", err)
+			}
+			written = append(written, digestFile)
+		}
+		if *altFlag != "" {
+			altFile := renderOutputName(*altFlag, map[string]string{"week": strconv.Itoa(week), "format": output.Format})
+			if err := writeAlternatives(collectAlternatives(weekInfo, schedule, opts.Trace), output.TaskLabel, altFile); err != nil {
+				log.Fatalf("Error writing alternatives: %v", err)
+			}
+			written = append(written, altFile)
+		}
+		if *assignRepFlag != "" {
+			reportFile := renderOutputName(*assignRepFlag, map[string]string{"week": strconv.Itoa(week), "format": output.Format})
+			if err := writeAssignReport(weekInfo, schedule, opts.Trace, output.TaskLabel, reportFile); err != nil {
+				log.Fatalf("Error writing assign report: %v", err)
+			}
+			written = append(written, reportFile)
+		}
+		previousSchedule = schedule
+		if state != nil && timeLimited {
+			// A partial week would skew the cumulative counts and debt
+			log.Printf("Week %d was cut short by the deadline and is not recorded in %s", week, *stateFlag)
+		} else if state != nil {
+			state.record(stateWeekKey(*weekFlag, stateStart, week), weekInfo, schedule, opts.RotationWeek)
+		}
+	}
+
+	printCadenceReport(info, weekBase, *weeksFlag)
+	if state != nil {
+		printStateSummary(state)
+		if err := saveState(state, *stateFlag); err != nil {
+			log.Fatalf("Error saving -state: %v", err)
+		}
+		written = append(written, *stateFlag)
 	}
 
 	// Print the number of tasks per person
@@ -384,7 +1115,14 @@ func main() {
 	// for user, count := range userTaskCount {
 	// 	fmt.Printf("%s: %d tasks\n", user, count)
 	// }
-	fmt.Println("\nSchedule generation complete! Check the weekly_schedule.csv file. Press Enter to exit.")
+	if len(written) == 1 {
+		fmt.Printf("\nSchedule generation complete! Check the %s file. Press Enter to exit.\n", written[0])
+	} else {
+		fmt.Printf("\nSchedule generation complete! Wrote %d files: %s. Press Enter to exit.\n", len(written), strings.Join(written, ", "))
+	}
 	// fmt.Scanln()
+	if timeLimited {
+		os.Exit(exitTimeLimited)
+	}
 
 }
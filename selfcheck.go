@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Violation describes a generated assignment that breaks one of the scheduling rules.
+type Violation struct {
+	Day    string
+	Task   string
+	User   string
+	Rule   string
+	Detail string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s / %s: %s violates %s (%s)", v.Day, v.Task, v.User, v.Rule, v.Detail)
+}
+
+// selfCheck re-validates a generated schedule against the constraints the generator is supposed to enforce.
+// Any violation returned here points at a bug in the assignment logic rather than at the input data.
+func selfCheck(info Info, schedule map[string]map[string]string, userTaskCount map[string]int, opts scheduleOptions) []Violation {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	tasksByName := make(map[string]Task)
+	for _, task := range info.Tasks {
+		tasksByName[task.Name] = task
+	}
+
+	var violations []Violation
+	for _, day := range info.DaysOfWeek {
+		taskNames := make([]string, 0, len(schedule[day]))
+		for taskName := range schedule[day] {
+			taskNames = append(taskNames, taskName)
+		}
+		sort.Strings(taskNames)
+
+		for _, taskName := range taskNames {
+			cell := schedule[day][taskName]
+			if cell == "" {
+				continue
+			}
+			task, ok := tasksByName[taskName]
+			if !ok {
+				violations = append(violations, Violation{day, taskName, cell, "task list", "task is not defined in info.json"})
+				continue
+			}
+			assignees := cellAssignees(cell)
+			_, max := taskRange(task)
+			if opts.PairTrainees {
+				max += supervisingTrainees(info, cell)
+			}
+			if len(assignees) > max && taskName != "Late Person Tasks" {
+				violations = append(violations, Violation{day, taskName, cell, "caps", fmt.Sprintf("%d assigned, at most %d allowed", len(assignees), max)})
+			}
+			for _, name := range assignees {
+				user, ok := usersByName[name]
+				if !ok {
+					violations = append(violations, Violation{day, taskName, name, "roster", "user is not defined in info.json"})
+					continue
+				}
+				if !isDedicated(task) && !contains(task.Days, day) {
+					violations = append(violations, Violation{day, taskName, name, "task days", "task does not run on " + day})
+				}
+				if !userHasTraining(user, task.RequiredTrainings) {
+					violations = append(violations, Violation{day, taskName, name, "training", fmt.Sprintf("requires %v", task.RequiredTrainings)})
+				}
+				if !isUserAvailable(user, day) {
+					violations = append(violations, Violation{day, taskName, name, "availability", "unavailable on " + day})
+				}
+				if overlapping := overlappingAssignment(schedule, info, task, day, name); overlapping != "" {
+					violations = append(violations, Violation{day, taskName, name, "conflicts", "time slot overlaps " + overlapping})
+				}
+				// Dedicated and rotation tasks keep one person on them by design
+				if !isDedicated(task) && len(task.RotationOrder) == 0 && !opts.Relaxations.relaxed(day, taskName, name, "spacing") {
+					if previousDay := previousDayOf(info.DaysOfWeek, day); previousDay != "" && cellHas(schedule[previousDay][taskName], name) {
+						violations = append(violations, Violation{day, taskName, name, "rest", "also assigned on " + previousDay})
+					}
+				}
+			}
+		}
+	}
+	counts := assignmentCounts(schedule)
+	for _, user := range info.Users {
+		if user.MaxTasks > 0 && counts[user.Name] > user.MaxTasks && !opts.SoftCap {
+			violations = append(violations, Violation{"(week)", "(all)", user.Name, "caps", fmt.Sprintf("%d tasks, cap is %d", counts[user.Name], user.MaxTasks)})
+		}
+		if count := len(distinctTasks(schedule, user.Name)); user.MaxDistinctTasks > 0 && count > user.MaxDistinctTasks && !opts.Relaxations.relaxedForUser(user.Name, "variety") {
+			violations = append(violations, Violation{"(week)", "(all)", user.Name, "caps", fmt.Sprintf("%d distinct tasks, cap is %d", count, user.MaxDistinctTasks)})
+		}
+	}
+	return append(violations, duplicateAssignments(info, schedule, userTaskCount)...)
+}
+
+// duplicateAssignments finds people listed twice in the same cell and users whose userTaskCount does
+// not match the cells they hold. The linked EOD Reports / Late Person Tasks pair is the usual suspect:
+// it is filled through a copy rather than a draw, and each half must be counted exactly once.
+func duplicateAssignments(info Info, schedule map[string]map[string]string, userTaskCount map[string]int) []Violation {
+	var violations []Violation
+	for _, day := range info.DaysOfWeek {
+		taskNames := make([]string, 0, len(schedule[day]))
+		for taskName := range schedule[day] {
+			taskNames = append(taskNames, taskName)
+		}
+		sort.Strings(taskNames)
+		for _, taskName := range taskNames {
+			seen := make(map[string]bool)
+			for _, name := range cellAssignees(schedule[day][taskName]) {
+				if seen[name] {
+					violations = append(violations, Violation{day, taskName, name, "duplicates", "assigned to the same slot twice"})
+				}
+				seen[name] = true
+			}
+		}
+	}
+
+	counts := assignmentCounts(schedule)
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	for name := range userTaskCount {
+		if _, ok := counts[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if counts[name] != userTaskCount[name] {
+			violations = append(violations, Violation{"(week)", "(all)", name, "counts", fmt.Sprintf("counted %d task(s) but holds %d", userTaskCount[name], counts[name])})
+		}
+	}
+	return violations
+}
+
+// contains reports whether list contains value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
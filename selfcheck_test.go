@@ -0,0 +1,120 @@
+package main
+
+import "testing"
+
+// eodInfo is a small week with the linked EOD Reports / Late Person Tasks pair and one other task.
+func eodInfo() Info {
+	days := []string{"Monday", "Tuesday", "Wednesday"}
+	return Info{
+		DaysOfWeek: days,
+		Users: []User{
+			{Name: "Ann", Trainings: []string{"eod"}},
+			{Name: "Bob", Trainings: []string{"eod"}},
+			{Name: "Cat"},
+		},
+		Tasks: []Task{
+			{Name: "EOD Reports", RequiredTrainings: []string{"eod"}, Days: days},
+			{Name: "Late Person Tasks", RequiredTrainings: []string{"eod"}, Days: days},
+			{Name: "Mail", Days: days},
+		},
+	}
+}
+
+func TestDuplicateAssignments(t *testing.T) {
+	schedule := func() map[string]map[string]string {
+		return map[string]map[string]string{
+			"Monday":    {"EOD Reports": "Ann", "Late Person Tasks": "Ann", "Mail": "Cat"},
+			"Tuesday":   {"EOD Reports": "Bob", "Late Person Tasks": "Bob", "Mail": "Cat"},
+			"Wednesday": {"EOD Reports": "Ann", "Late Person Tasks": "Ann", "Mail": "Cat"},
+		}
+	}
+	tests := []struct {
+		name     string
+		schedule map[string]map[string]string
+		counts   map[string]int
+		want     []Violation
+	}{
+		{
+			name:     "consistent",
+			schedule: schedule(),
+			counts:   map[string]int{"Ann": 4, "Bob": 2, "Cat": 3},
+		},
+		{
+			name:     "EOD copy counted twice",
+			schedule: schedule(),
+			counts:   map[string]int{"Ann": 5, "Bob": 2, "Cat": 3},
+			want:     []Violation{{"(week)", "(all)", "Ann", "counts", "counted 5 task(s) but holds 4"}},
+		},
+		{
+			name:     "EOD copy not counted",
+			schedule: schedule(),
+			counts:   map[string]int{"Ann": 4, "Bob": 1, "Cat": 3},
+			want:     []Violation{{"(week)", "(all)", "Bob", "counts", "counted 1 task(s) but holds 2"}},
+		},
+		{
+			name: "same name twice in a cell",
+			schedule: func() map[string]map[string]string {
+				s := schedule()
+				s["Tuesday"]["Mail"] = "Cat, Cat"
+				return s
+			}(),
+			counts: map[string]int{"Ann": 4, "Bob": 2, "Cat": 4},
+			want:   []Violation{{"Tuesday", "Mail", "Cat", "duplicates", "assigned to the same slot twice"}},
+		},
+		{
+			name:     "counted but holds nothing",
+			schedule: schedule(),
+			counts:   map[string]int{"Ann": 4, "Bob": 2, "Cat": 3, "Dan": 1},
+			want:     []Violation{{"(week)", "(all)", "Dan", "counts", "counted 1 task(s) but holds 0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := duplicateAssignments(eodInfo(), tt.schedule, tt.counts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("duplicateAssignments() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("violation %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateAssignmentsCleanWeek(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		useSeed(t, seed)
+		info := eodInfo()
+		schedule, userTaskCount := generateWeeklySchedule(info, nil, scheduleOptions{})
+		if violations := duplicateAssignments(info, schedule, userTaskCount); len(violations) > 0 {
+			t.Fatalf("seed %d: generated week reports %v", seed, violations)
+		}
+	}
+}
+
+func TestDedicatedRoleSkipsUnavailableUsers(t *testing.T) {
+	days := []string{"Monday", "Tuesday", "Wednesday"}
+	for seed := int64(1); seed <= 50; seed++ {
+		useSeed(t, seed)
+		info := Info{
+			DaysOfWeek: days,
+			Users: []User{
+				{Name: "Ann", Trainings: []string{"desk"}, DaysUnavailable: []string{"Monday"}},
+				{Name: "Bob", Trainings: []string{"desk"}, DaysUnavailable: []string{"Tuesday"}},
+				{Name: "Cat", Trainings: []string{"desk"}, DaysUnavailable: []string{"Wednesday"}},
+			},
+			Tasks: []Task{{Name: "Desk", RequiredTrainings: []string{"desk"}, Days: days, Notes: "same person all week"}},
+		}
+		schedule, userTaskCount := generateWeeklySchedule(info, nil, scheduleOptions{})
+		if violations := selfCheck(info, schedule, userTaskCount, scheduleOptions{}); len(violations) > 0 {
+			t.Fatalf("seed %d: self-check reports %v", seed, violations)
+		}
+		for _, day := range days {
+			if schedule[day]["Desk"] == "" {
+				t.Fatalf("seed %d: Desk left unfilled on %s", seed, day)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// parseClock converts an "HH:MM" time of day into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// taskSlot returns the time slot of a task in minutes after midnight.
+// ok is false for tasks without time information, which never overlap anything.
+func taskSlot(task Task) (start, end int, ok bool) {
+	if task.Start == "" || task.End == "" {
+		return 0, 0, false
+	}
+	start, err := parseClock(task.Start)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err = parseClock(task.End)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// slotsOverlap reports whether two tasks have time slots that intersect.
+func slotsOverlap(a, b Task) bool {
+	aStart, aEnd, ok := taskSlot(a)
+	if !ok {
+		return false
+	}
+	bStart, bEnd, ok := taskSlot(b)
+	if !ok {
+		return false
+	}
+	return aStart < bEnd && bStart < aEnd
+}
+
+// overlappingAssignment returns the name of a task the user already holds on day whose
+// time slot overlaps task, or "" if there is none. EOD Reports and Late Person Tasks are
+// held by the same person by design and are never treated as a conflict.
+func overlappingAssignment(schedule map[string]map[string]string, info Info, task Task, day string, userName string) string {
+	for _, other := range info.Tasks {
+		if other.Name == task.Name || isLinkedPair(task.Name, other.Name) || !cellHas(schedule[day][other.Name], userName) {
+			continue
+		}
+		if slotsOverlap(task, other) {
+			return other.Name
+		}
+	}
+	return ""
+}
+
+// hasOverlapDuringWeek reports whether the user holds a slot overlapping task on any day of the week.
+func hasOverlapDuringWeek(schedule map[string]map[string]string, info Info, task Task, userName string) bool {
+	for _, day := range info.DaysOfWeek {
+		if overlappingAssignment(schedule, info, task, day, userName) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isLinkedPair reports whether two tasks are always assigned to the same person.
+func isLinkedPair(a, b string) bool {
+	return (a == "EOD Reports" && b == "Late Person Tasks") || (a == "Late Person Tasks" && b == "EOD Reports")
+}
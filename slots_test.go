@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// useSeed points the package rng at seed for the rest of the test and restores the previous rng when
+// the test ends, so seeded tests do not leak state into each other.
+func useSeed(t *testing.T, seed int64) {
+	t.Helper()
+	saved := rng
+	t.Cleanup(func() { rng = saved })
+	rng = rand.New(rand.NewSource(seed))
+}
+
+func TestSlotsOverlap(t *testing.T) {
+	timed := func(start, end string) Task { return Task{Name: start + "-" + end, Start: start, End: end} }
+	tests := []struct {
+		name string
+		a, b Task
+		want bool
+	}{
+		{"disjoint", timed("08:00", "09:00"), timed("10:00", "11:00"), false},
+		{"partial overlap", timed("08:00", "10:00"), timed("09:00", "11:00"), true},
+		{"touching edges", timed("08:00", "09:00"), timed("09:00", "10:00"), false},
+		{"touching edges reversed", timed("09:00", "10:00"), timed("08:00", "09:00"), false},
+		{"nested", timed("08:00", "12:00"), timed("09:00", "10:00"), true},
+		{"nested reversed", timed("09:00", "10:00"), timed("08:00", "12:00"), true},
+		{"identical", timed("08:00", "09:00"), timed("08:00", "09:00"), true},
+		{"first untimed", Task{Name: "Untimed"}, timed("08:00", "09:00"), false},
+		{"second untimed", timed("08:00", "09:00"), Task{Name: "Untimed"}, false},
+		{"both untimed", Task{Name: "A"}, Task{Name: "B"}, false},
+		{"start only", Task{Name: "A", Start: "08:00"}, timed("08:00", "09:00"), false},
+		{"malformed", Task{Name: "A", Start: "8am", End: "9am"}, timed("08:00", "09:00"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slotsOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("slotsOverlap(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlappingAssignment(t *testing.T) {
+	info := Info{
+		DaysOfWeek: []string{"Monday"},
+		Tasks: []Task{
+			{Name: "Morning", Start: "08:00", End: "10:00"},
+			{Name: "Brunch", Start: "09:00", End: "11:00"},
+			{Name: "Noon", Start: "10:00", End: "12:00"},
+			{Name: "Untimed"},
+			{Name: "EOD Reports", Start: "16:00", End: "17:00"},
+			{Name: "Late Person Tasks", Start: "16:30", End: "18:00"},
+		},
+	}
+	tasks := make(map[string]Task)
+	for _, task := range info.Tasks {
+		tasks[task.Name] = task
+	}
+	tests := []struct {
+		name     string
+		schedule map[string]string
+		task     string
+		user     string
+		want     string
+	}{
+		{"free day", map[string]string{}, "Morning", "Ann", ""},
+		{"overlapping slot held", map[string]string{"Morning": "Ann"}, "Brunch", "Ann", "Morning"},
+		{"held by someone else", map[string]string{"Morning": "Bob"}, "Brunch", "Ann", ""},
+		{"held in a shared cell", map[string]string{"Morning": "Bob, Ann"}, "Brunch", "Ann", "Morning"},
+		{"touching edges", map[string]string{"Morning": "Ann"}, "Noon", "Ann", ""},
+		{"same task", map[string]string{"Morning": "Ann"}, "Morning", "Ann", ""},
+		{"untimed task", map[string]string{"Morning": "Ann"}, "Untimed", "Ann", ""},
+		{"untimed held", map[string]string{"Untimed": "Ann"}, "Morning", "Ann", ""},
+		{"EOD/Late pair", map[string]string{"EOD Reports": "Ann"}, "Late Person Tasks", "Ann", ""},
+		{"Late/EOD pair", map[string]string{"Late Person Tasks": "Ann"}, "EOD Reports", "Ann", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := map[string]map[string]string{"Monday": tt.schedule}
+			if got := overlappingAssignment(schedule, info, tasks[tt.task], "Monday", tt.user); got != tt.want {
+				t.Errorf("overlappingAssignment(%s, %s) = %q, want %q", tt.task, tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWeeklyScheduleNoOverlappingDoubleBooking(t *testing.T) {
+	days := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+	for seed := int64(1); seed <= 50; seed++ {
+		useSeed(t, seed)
+		info := Info{
+			DaysOfWeek: days,
+			Users: []User{
+				{Name: "Ann", Trainings: []string{"desk"}},
+				{Name: "Bob", Trainings: []string{"desk"}},
+				{Name: "Cat", Trainings: []string{"desk"}},
+			},
+			Tasks: []Task{
+				{Name: "Front Desk", RequiredTrainings: []string{"desk"}, Days: days, Start: "08:00", End: "12:00"},
+				{Name: "Phones", RequiredTrainings: []string{"desk"}, Days: days, Start: "11:00", End: "13:00"},
+			},
+		}
+		schedule, _ := generateWeeklySchedule(info, nil, scheduleOptions{})
+		for _, day := range days {
+			desk, phones := schedule[day]["Front Desk"], schedule[day]["Phones"]
+			if desk == "" || phones == "" {
+				t.Fatalf("seed %d: %s left unfilled (Front Desk %q, Phones %q)", seed, day, desk, phones)
+			}
+			for _, name := range cellAssignees(desk) {
+				if cellHas(phones, name) {
+					t.Fatalf("seed %d: %s holds both overlapping slots on %s", seed, name, day)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// stateVersion is the version written to new state files. Bump it, and add a step to migrateState,
+// whenever the meaning or layout of State changes.
+const stateVersion = 2
+
+// State is what -state carries from one run to the next so fairness can be judged over many weeks
+// rather than one schedule at a time. Every week is recorded under its key (see stateWeekKey), so
+// generating the same week again replaces its earlier record instead of counting it twice.
+type State struct {
+	Version int `json:"version"`
+	// Weeks is how many distinct weeks have been recorded.
+	Weeks int `json:"weeks"`
+	// Counts is each user's cumulative number of assignments over all recorded weeks.
+	Counts map[string]int `json:"counts"`
+	// Debt is each user's cumulative assignments minus their fair share: every week adds the user's
+	// count minus that week's average over the roster. Positive means they have done more than their share.
+	// Saved files keep debtPrecision decimals.
+	Debt map[string]float64 `json:"debt"`
+	// RotationWeek is the rotation_order week index of the most recently recorded week. It is kept for
+	// reference; the week being scheduled always comes from -week, -start-date or today.
+	RotationWeek int `json:"rotation_week"`
+	// History holds each recorded week's own counts and debt by week key. Weeks recorded by version 1
+	// files are only part of the totals above.
+	History map[string]stateWeek `json:"history"`
+}
+
+// stateWeek is one recorded week's contribution to the cumulative counts and debt.
+type stateWeek struct {
+	RotationWeek int                `json:"rotation_week"`
+	Counts       map[string]int     `json:"counts"`
+	Debt         map[string]float64 `json:"debt"`
+}
+
+// stateWeekKey identifies week k (1-based) of a run in the state file: the ISO week of start plus k-1
+// weeks, such as "2026-W42", or "week 7" when -week gave the index directly.
+func stateWeekKey(week int, start time.Time, k int) string {
+	if week != 0 {
+		return fmt.Sprintf("week %d", week+k-1)
+	}
+	year, isoWeek := start.AddDate(0, 0, 7*(k-1)).ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, isoWeek)
+}
+
+// debtPrecision is how many decimals of debt are saved, so state files diff cleanly from week to week
+// instead of carrying float noise such as 1.1102230246251565e-16.
+const debtPrecision = 2
+
+// roundDebt rounds a debt value to debtPrecision decimals, normalizing -0 to 0.
+func roundDebt(debt float64) float64 {
+	scale := math.Pow(10, debtPrecision)
+	if rounded := math.Round(debt*scale) / scale; rounded != 0 {
+		return rounded
+	}
+	return 0
+}
+
+// newState returns an empty state at the current version.
+func newState() *State {
+	return &State{Version: stateVersion, Counts: make(map[string]int), Debt: make(map[string]float64), History: make(map[string]stateWeek)}
+}
+
+// loadState reads a state file, returning an empty state when it does not exist yet.
+func loadState(filename string) (*State, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return newState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := newState()
+	state.Version = 0 // files without a version predate versioning
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, migrateState(state)
+}
+
+// migrateState upgrades an older state in place to stateVersion. Files from a newer scheduler are
+// rejected rather than guessed at.
+func migrateState(state *State) error {
+	if state.Version > stateVersion {
+		return fmt.Errorf("state file version %d is newer than this scheduler supports (%d)", state.Version, stateVersion)
+	}
+	if state.Version == 0 {
+		// Unversioned files have the version 1 layout; only missing maps need filling in
+		state.Version = 1
+	}
+	if state.Version == 1 {
+		// Version 1 kept totals only; they stay as they are and later weeks are recorded by key
+		state.Version = 2
+	}
+	if state.Counts == nil {
+		state.Counts = make(map[string]int)
+	}
+	if state.Debt == nil {
+		state.Debt = make(map[string]float64)
+	}
+	if state.History == nil {
+		state.History = make(map[string]stateWeek)
+	}
+	return nil
+}
+
+// record adds one generated week to the state under key. A week already recorded under the same key
+// is taken out of the totals first, so regenerating a week replaces it.
+func (s *State) record(key string, info Info, schedule map[string]map[string]string, rotationWeek int) {
+	if old, ok := s.History[key]; ok {
+		for name, count := range old.Counts {
+			s.Counts[name] -= count
+		}
+		for name, debt := range old.Debt {
+			s.Debt[name] -= debt
+		}
+	} else {
+		s.Weeks++
+	}
+
+	counts := assignmentCounts(schedule)
+	total := 0
+	for _, user := range info.Users {
+		total += counts[user.Name]
+	}
+	average := 0.0
+	if len(info.Users) > 0 {
+		average = float64(total) / float64(len(info.Users))
+	}
+	week := stateWeek{RotationWeek: rotationWeek, Counts: make(map[string]int), Debt: make(map[string]float64)}
+	for _, user := range info.Users {
+		week.Counts[user.Name] = counts[user.Name]
+		week.Debt[user.Name] = float64(counts[user.Name]) - average
+		s.Counts[user.Name] += week.Counts[user.Name]
+		s.Debt[user.Name] += week.Debt[user.Name]
+	}
+	s.History[key] = week
+	s.RotationWeek = rotationWeek
+}
+
+// saveState writes the state as indented JSON, with debt rounded to debtPrecision decimals.
+func saveState(state *State, filename string) error {
+	round := func(debts map[string]float64) map[string]float64 {
+		rounded := make(map[string]float64, len(debts))
+		for name, debt := range debts {
+			rounded[name] = roundDebt(debt)
+		}
+		return rounded
+	}
+	saved := *state
+	saved.Debt = round(state.Debt)
+	saved.History = make(map[string]stateWeek, len(state.History))
+	for key, week := range state.History {
+		week.Debt = round(week.Debt)
+		saved.History[key] = week
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
+
+// printStateSummary prints the cumulative counts and debt per user, most over-assigned first.
+func printStateSummary(state *State) {
+	names := make([]string, 0, len(state.Counts))
+	for name := range state.Counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if state.Debt[names[i]] != state.Debt[names[j]] {
+			return state.Debt[names[i]] > state.Debt[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	fmt.Printf("Cumulative state over %d week(s):\n", state.Weeks)
+	for _, name := range names {
+		fmt.Printf("  %s: %d assignments, debt %+.1f\n", name, state.Counts[name], state.Debt[name])
+	}
+}
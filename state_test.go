@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveStateRoundsDebt(t *testing.T) {
+	info := Info{
+		DaysOfWeek: []string{"Monday"},
+		Users:      []User{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cat"}},
+	}
+	// One assignment over three users: an average of 1/3, which never sums back to whole numbers exactly
+	schedule := map[string]map[string]string{"Monday": {"Mail": "Ann"}}
+	state := newState()
+	for week := 1; week <= 4; week++ {
+		state.record(fmt.Sprintf("week %d", week), info, schedule, week)
+	}
+
+	filename := filepath.Join(t.TempDir(), "state.json")
+	if err := saveState(state, filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved State
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"Ann": 2.67, "Bob": -1.33, "Cat": -1.33}
+	if !reflect.DeepEqual(saved.Debt, want) {
+		t.Errorf("saved debt = %v, want %v", saved.Debt, want)
+	}
+	if state.Debt["Ann"] == 2.67 {
+		t.Error("saveState rounded the in-memory debt; only the file should be rounded")
+	}
+}
+
+func TestRoundDebt(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{1.1102230246251565e-16, 0},
+		{-1.1102230246251565e-16, 0},
+		{-0.9999999999999999, -1},
+		{0.666666, 0.67},
+		{-0.335, -0.34},
+	}
+	for _, tt := range tests {
+		if got := roundDebt(tt.in); got != tt.want {
+			t.Errorf("roundDebt(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if data, _ := json.Marshal(roundDebt(-1e-9)); strings.HasPrefix(string(data), "-") {
+		t.Errorf("roundDebt(-1e-9) encodes as %s, want 0", data)
+	}
+}
+
+func TestStateRecordReplacesSameWeek(t *testing.T) {
+	info := Info{
+		DaysOfWeek: []string{"Monday"},
+		Users:      []User{{Name: "Ann"}, {Name: "Bob"}},
+	}
+	state := newState()
+	state.record("2026-W42", info, map[string]map[string]string{"Monday": {"Mail": "Ann"}}, 42)
+	state.record("2026-W42", info, map[string]map[string]string{"Monday": {"Mail": "Bob"}}, 42)
+	if state.Weeks != 1 {
+		t.Errorf("Weeks = %d after regenerating one week, want 1", state.Weeks)
+	}
+	if want := map[string]int{"Ann": 0, "Bob": 1}; !reflect.DeepEqual(state.Counts, want) {
+		t.Errorf("Counts = %v, want %v", state.Counts, want)
+	}
+	if want := map[string]float64{"Ann": -0.5, "Bob": 0.5}; !reflect.DeepEqual(state.Debt, want) {
+		t.Errorf("Debt = %v, want %v", state.Debt, want)
+	}
+
+	state.record("2026-W43", info, map[string]map[string]string{"Monday": {"Mail": "Bob"}}, 43)
+	if state.Weeks != 2 || state.Counts["Bob"] != 2 || state.RotationWeek != 43 {
+		t.Errorf("after a new week: Weeks %d, Bob %d, RotationWeek %d; want 2, 2, 43", state.Weeks, state.Counts["Bob"], state.RotationWeek)
+	}
+}
+
+func TestStateWeekKey(t *testing.T) {
+	start := time.Date(2026, time.December, 28, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		week, k int
+		want    string
+	}{
+		{0, 1, "2026-W53"},
+		{0, 2, "2027-W01"},
+		{7, 1, "week 7"},
+		{7, 3, "week 9"},
+	}
+	for _, tt := range tests {
+		if got := stateWeekKey(tt.week, start, tt.k); got != tt.want {
+			t.Errorf("stateWeekKey(%d, %s, %d) = %q, want %q", tt.week, start.Format("2006-01-02"), tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStateMigratesVersion1(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	v1 := `{"version": 1, "weeks": 3, "counts": {"Ann": 9}, "debt": {"Ann": 1.5}, "rotation_week": 12}`
+	if err := os.WriteFile(filename, []byte(v1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadState(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != stateVersion || state.Weeks != 3 || state.Counts["Ann"] != 9 || state.History == nil {
+		t.Errorf("migrated state = %+v, want version %d keeping the version 1 totals", state, stateVersion)
+	}
+}
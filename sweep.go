@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sweepFrequency is how often one person landed one day/task slot across a seed sweep.
+type sweepFrequency struct {
+	Day      string  `json:"day"`
+	Task     string  `json:"task"`
+	Assignee string  `json:"assignee"`
+	Count    int     `json:"count"`
+	Share    float64 `json:"share"`
+}
+
+// sweepStats summarizes a distribution of per-run values.
+type sweepStats struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// seedSweepReport is the result of generating the same week under many seeds.
+type seedSweepReport struct {
+	Runs      int              `json:"runs"`
+	Seeds     []int64          `json:"seeds"`
+	Frequency []sweepFrequency `json:"frequency"`
+	// FairnessSpread is the gap between the most and least loaded user in each run.
+	FairnessSpread sweepStats `json:"fairness_spread"`
+	// Gaps is the number of unfilled day/task slots in each run.
+	Gaps sweepStats `json:"gaps"`
+}
+
+// runSeedSweep generates the week once per seed, starting at firstSeed, and collects how often each
+// person was assigned each slot along with fairness-spread and gap statistics. Generation logs are
+// silenced while sweeping. Once opts' deadline passes the sweep stops with the runs completed so far.
+func runSeedSweep(info Info, previousSchedule map[string]map[string]string, opts scheduleOptions, firstSeed int64, runs int) seedSweepReport {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	report := seedSweepReport{}
+	counts := make(map[[3]string]int)
+	var spreads, gaps []int
+	for i := 0; i < runs; i++ {
+		seed := firstSeed + int64(i)
+		rng = rand.New(rand.NewSource(seed))
+
+		// Every run starts from the same user order so the seed alone decides the outcome
+		runInfo := info
+		runInfo.Users = append([]User{}, info.Users...)
+		schedule, userTaskCount := generateWeeklySchedule(runInfo, previousSchedule, opts)
+		if opts.expired() {
+			break // a run cut short by the deadline would skew the statistics
+		}
+		report.Runs++
+		report.Seeds = append(report.Seeds, seed)
+
+		for day, cells := range schedule {
+			for task, cell := range cells {
+				for _, name := range cellAssignees(cell) {
+					counts[[3]string{day, task, name}]++
+				}
+			}
+		}
+		spreads = append(spreads, loadSpread(info, userTaskCount))
+		gaps = append(gaps, countGaps(info, schedule))
+	}
+
+	dayIndex := make(map[string]int)
+	for i, day := range info.DaysOfWeek {
+		dayIndex[day] = i
+	}
+	for key, count := range counts {
+		report.Frequency = append(report.Frequency, sweepFrequency{
+			Day: key[0], Task: key[1], Assignee: key[2], Count: count, Share: float64(count) / float64(report.Runs),
+		})
+	}
+	sort.Slice(report.Frequency, func(i, j int) bool {
+		a, b := report.Frequency[i], report.Frequency[j]
+		if a.Day != b.Day {
+			return dayIndex[a.Day] < dayIndex[b.Day]
+		}
+		if a.Task != b.Task {
+			return a.Task < b.Task
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Assignee < b.Assignee
+	})
+	report.FairnessSpread = summarize(spreads)
+	report.Gaps = summarize(gaps)
+	return report
+}
+
+// loadSpread is the difference between the highest and lowest assignment count across all users.
+func loadSpread(info Info, userTaskCount map[string]int) int {
+	if len(info.Users) == 0 {
+		return 0
+	}
+	min, max := userTaskCount[info.Users[0].Name], userTaskCount[info.Users[0].Name]
+	for _, user := range info.Users {
+		count := userTaskCount[user.Name]
+		if count < min {
+			min = count
+		}
+		if count > max {
+			max = count
+		}
+	}
+	return max - min
+}
+
+// countGaps counts the day/task slots that ended up with nobody assigned.
+func countGaps(info Info, schedule map[string]map[string]string) int {
+	gaps := 0
+	for _, task := range info.Tasks {
+		for _, day := range task.Days {
+			if schedule[day][task.Name] == "" {
+				gaps++
+			}
+		}
+	}
+	return gaps
+}
+
+func summarize(values []int) sweepStats {
+	if len(values) == 0 {
+		return sweepStats{}
+	}
+	stats := sweepStats{Min: values[0], Max: values[0]}
+	total := 0
+	for _, value := range values {
+		total += value
+		if value < stats.Min {
+			stats.Min = value
+		}
+		if value > stats.Max {
+			stats.Max = value
+		}
+	}
+	stats.Mean = float64(total) / float64(len(values))
+	return stats
+}
+
+// writeSeedSweepReport writes the report as JSON when filename ends in .json and as CSV otherwise, and
+// returns the files written. The CSV variant puts the frequency table in filename and the run count,
+// seeds and summary statistics in a Statistic,Value file beside it (see sweepSummaryFile), so both
+// variants carry the same data. The summary statistics are also printed.
+func writeSeedSweepReport(report seedSweepReport, filename string) ([]string, error) {
+	fmt.Printf("Seed sweep over %d run(s):\n", report.Runs)
+	fmt.Printf("  fairness spread: min %d, max %d, mean %.2f\n", report.FairnessSpread.Min, report.FairnessSpread.Max, report.FairnessSpread.Mean)
+	fmt.Printf("  unfilled slots:  min %d, max %d, mean %.2f\n", report.Gaps.Min, report.Gaps.Max, report.Gaps.Mean)
+
+	if filepath.Ext(filename) == ".json" {
+		file, err := os.Create(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return []string{filename}, encoder.Encode(report)
+	}
+
+	rows := [][]string{{"Day", "Task", "Assignee", "Count", "Share"}}
+	for _, f := range report.Frequency {
+		rows = append(rows, []string{f.Day, f.Task, f.Assignee, strconv.Itoa(f.Count), strconv.FormatFloat(f.Share, 'f', 3, 64)})
+	}
+	if err := writeCSVRows(filename, rows); err != nil {
+		return nil, err
+	}
+
+	summary := [][]string{{"Statistic", "Value"}, {"runs", strconv.Itoa(report.Runs)}}
+	if len(report.Seeds) > 0 {
+		summary = append(summary,
+			[]string{"first_seed", strconv.FormatInt(report.Seeds[0], 10)},
+			[]string{"last_seed", strconv.FormatInt(report.Seeds[len(report.Seeds)-1], 10)})
+	}
+	for _, stat := range []struct {
+		name  string
+		stats sweepStats
+	}{{"fairness_spread", report.FairnessSpread}, {"gaps", report.Gaps}} {
+		summary = append(summary,
+			[]string{stat.name + "_min", strconv.Itoa(stat.stats.Min)},
+			[]string{stat.name + "_max", strconv.Itoa(stat.stats.Max)},
+			[]string{stat.name + "_mean", strconv.FormatFloat(stat.stats.Mean, 'f', 3, 64)})
+	}
+	summaryFile := sweepSummaryFile(filename)
+	if err := writeCSVRows(summaryFile, summary); err != nil {
+		return []string{filename}, err
+	}
+	return []string{filename, summaryFile}, nil
+}
+
+// sweepSummaryFile returns the name of the summary CSV written next to a CSV sweep report, e.g.
+// sweep_summary.csv for sweep.csv.
+func sweepSummaryFile(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "_summary" + ext
+}
+
+// writeCSVRows writes rows to a new CSV file.
+func writeCSVRows(filename string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
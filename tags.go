@@ -0,0 +1,46 @@
+package main
+
+import "strings"
+
+// parseTagList splits a comma-separated -with-tag/-without-tag value, dropping empty entries.
+func parseTagList(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// filterUsersByTag returns a copy of info whose roster keeps only users carrying every tag in with
+// and none of the tags in without. It runs after loading, so group unavailability is already merged
+// into the remaining users. Everything downstream sees only the filtered roster: users filtered out
+// cannot be drawn, paired as trainers or used as day_overrides backups, and their bids are ignored.
+func filterUsersByTag(info Info, with, without []string) Info {
+	filtered := info
+	filtered.Users = nil
+	for _, user := range info.Users {
+		keep := true
+		for _, tag := range with {
+			keep = keep && contains(user.Tags, tag)
+		}
+		for _, tag := range without {
+			keep = keep && !contains(user.Tags, tag)
+		}
+		if keep {
+			filtered.Users = append(filtered.Users, user)
+		}
+	}
+	return filtered
+}
+
+// rosterHas reports whether info's roster includes a user called name.
+func rosterHas(info Info, name string) bool {
+	for _, user := range info.Users {
+		if user.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// candidateDecision is one user's standing when a slot was drawn: their load at the time and,
+// if they were excluded, the reason why.
+type candidateDecision struct {
+	Name   string
+	Load   int
+	Reason string
+}
+
+// slotDecision captures a single draw made by assignTask. Multi-person slots have one draw per pick.
+type slotDecision struct {
+	Day        string
+	Task       string
+	Candidates []candidateDecision
+	Pool       []string // least-loaded eligible users the winner was drawn from
+	Winner     string   // empty when nobody could be assigned
+}
+
+// decisionTrace collects balancer decisions in the order they were made.
+type decisionTrace struct {
+	Decisions []slotDecision
+}
+
+func (t *decisionTrace) record(decision slotDecision) {
+	t.Decisions = append(t.Decisions, decision)
+}
+
+// forSlot returns the decisions made for a day/task cell in order.
+func (t *decisionTrace) forSlot(day, task string) []slotDecision {
+	var decisions []slotDecision
+	for _, decision := range t.Decisions {
+		if decision.Day == day && decision.Task == task {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
+
+// explainWhyNot answers a NAME:Task:Day query with the constraint that kept the user out of the slot,
+// or the user who beat them in the random draw. Task names may themselves contain colons. rotationWeek
+// is the week index rotation_order tasks were filled with.
+func explainWhyNot(query string, info Info, schedule map[string]map[string]string, trace *decisionTrace, rotationWeek int) (string, error) {
+	first, last := strings.Index(query, ":"), strings.LastIndex(query, ":")
+	if first < 0 || first == last {
+		return "", fmt.Errorf("expected NAME:Task:Day, got %q", query)
+	}
+	name, taskName, day := query[:first], query[first+1:last], query[last+1:]
+
+	var user *User
+	for i := range info.Users {
+		if info.Users[i].Name == name {
+			user = &info.Users[i]
+		}
+	}
+	if user == nil {
+		return "", fmt.Errorf("unknown user %q", name)
+	}
+	var task *Task
+	for i := range info.Tasks {
+		if info.Tasks[i].Name == taskName {
+			task = &info.Tasks[i]
+		}
+	}
+	if task == nil {
+		return "", fmt.Errorf("unknown task %q", taskName)
+	}
+	if !contains(info.DaysOfWeek, day) {
+		return "", fmt.Errorf("unknown day %q", day)
+	}
+
+	cell := schedule[day][taskName]
+	if cellHas(cell, name) {
+		return fmt.Sprintf("%s was assigned %s on %s.", name, taskName, day), nil
+	}
+	if !isDedicated(*task) && !contains(task.Days, day) {
+		return fmt.Sprintf("%s does not run on %s.", taskName, day), nil
+	}
+
+	decisions := trace.forSlot(day, taskName)
+	if len(decisions) == 0 {
+		switch {
+		case isDedicated(*task):
+			return fmt.Sprintf("%s is a dedicated all-week role and went to %s before the daily draws.", taskName, displayName(cell)), nil
+		case len(task.RotationOrder) > 0:
+			turn := task.RotationOrder[rotationWeek%len(task.RotationOrder)]
+			if cellHas(cell, turn) {
+				return fmt.Sprintf("%s follows rotation_order and it was %s's turn (week index %d).", taskName, turn, rotationWeek), nil
+			}
+			return fmt.Sprintf("%s follows rotation_order and it was %s's turn (week index %d); %s was skipped on %s (%s), so it went to %s as next in order.",
+				taskName, turn, rotationWeek, turn, day, rotationSkipReason(info, turn, day), displayName(cell)), nil
+		case taskName == "Late Person Tasks":
+			return fmt.Sprintf("Late Person Tasks follows EOD Reports, which went to %s on %s.", displayName(cell), day), nil
+		default:
+			return fmt.Sprintf("%s on %s was a fixed assignment (preserved or bid) held by %s.", taskName, day, displayName(cell)), nil
+		}
+	}
+
+	var lines []string
+	for i, decision := range decisions {
+		prefix := ""
+		if len(decisions) > 1 {
+			prefix = fmt.Sprintf("Pick %d: ", i+1)
+		}
+		lines = append(lines, prefix+explainDecision(name, decision))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// explainDecision describes how name fared in a single draw.
+func explainDecision(name string, decision slotDecision) string {
+	for _, candidate := range decision.Candidates {
+		if candidate.Name != name {
+			continue
+		}
+		if candidate.Reason != "" {
+			return fmt.Sprintf("%s was excluded: %s.", name, candidate.Reason)
+		}
+		if !contains(decision.Pool, name) {
+			return fmt.Sprintf("%s was eligible but had %d task(s), more than the least-loaded pool %v.", name, candidate.Load, decision.Pool)
+		}
+		return fmt.Sprintf("%s was eligible and in the draw with %v, but lost the random draw to %s.", name, decision.Pool, displayName(decision.Winner))
+	}
+	return fmt.Sprintf("%s was not considered for this slot.", name)
+}
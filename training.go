@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// isTrainee reports whether the named user is flagged as a trainee.
+func isTrainee(info Info, name string) bool {
+	for _, user := range info.Users {
+		if user.Name == name {
+			return user.Trainee
+		}
+	}
+	return false
+}
+
+// isTrainerFor reports whether user can supervise trainees on the task.
+func isTrainerFor(user User, taskName string) bool {
+	return !user.Trainee && contains(user.TrainerFor, taskName)
+}
+
+// pairTrainer adds the least-loaded eligible trainer for task to the day's cell, joining the trainee
+// already drawn there. It reports false when no trainer could be added.
+func pairTrainer(
+	schedule map[string]map[string]string,
+	info Info,
+	task Task,
+	day string,
+	userTaskCount map[string]int,
+	previousSchedule map[string]map[string]string) bool {
+
+	var trainers []User
+	for _, user := range info.Users {
+		if isTrainerFor(user, task.Name) && exclusionReason(schedule, info, task, day, user, userTaskCount, previousSchedule, nil) == "" {
+			trainers = append(trainers, user)
+		}
+	}
+	if len(trainers) == 0 {
+		return false
+	}
+	rng.Shuffle(len(trainers), func(i, j int) { trainers[i], trainers[j] = trainers[j], trainers[i] })
+	sort.SliceStable(trainers, func(i, j int) bool {
+		return userTaskCount[trainers[i].Name] < userTaskCount[trainers[j].Name]
+	})
+
+	trainer := trainers[0]
+	schedule[day][task.Name] = addAssignee(schedule[day][task.Name], trainer.Name)
+	userTaskCount[trainer.Name]++
+	return true
+}
+
+// unsupervisedTrainees returns the trainees in a task's cell when nobody in the cell is a trainer for
+// the task. The generator's warnings and printTraineeReport both go by this check.
+func unsupervisedTrainees(info Info, taskName string, cell string) []string {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+	var trainees []string
+	for _, name := range cellAssignees(cell) {
+		if isTrainerFor(usersByName[name], distinctTaskKey(taskName)) {
+			return nil
+		}
+		if usersByName[name].Trainee {
+			trainees = append(trainees, name)
+		}
+	}
+	return trainees
+}
+
+// supervisingTrainees counts the trainees in a cell, each of whom may bring one trainer beyond MaxCount.
+func supervisingTrainees(info Info, cell string) int {
+	count := 0
+	for _, name := range cellAssignees(cell) {
+		if isTrainee(info, name) {
+			count++
+		}
+	}
+	return count
+}
+
+// printTraineeReport lists each trainee assignment as supervised, when a trainer for the task shares
+// the slot, or unsupervised.
+func printTraineeReport(info Info, schedule map[string]map[string]string) {
+	usersByName := make(map[string]User)
+	for _, user := range info.Users {
+		usersByName[user.Name] = user
+	}
+
+	supervised, unsupervised := 0, 0
+	var lines []string
+	for _, day := range info.DaysOfWeek {
+		tasks := make([]string, 0, len(schedule[day]))
+		for task := range schedule[day] {
+			tasks = append(tasks, task)
+		}
+		sort.Strings(tasks)
+		for _, task := range tasks {
+			unsupervisedNames := unsupervisedTrainees(info, task, schedule[day][task])
+			for _, name := range cellAssignees(schedule[day][task]) {
+				if !usersByName[name].Trainee {
+					continue
+				}
+				if !contains(unsupervisedNames, name) {
+					supervised++
+					lines = append(lines, fmt.Sprintf("  %s / %s: %s supervised", day, task, name))
+				} else {
+					unsupervised++
+					lines = append(lines, fmt.Sprintf("  %s / %s: %s unsupervised", day, task, name))
+				}
+			}
+		}
+	}
+	if supervised+unsupervised == 0 {
+		return
+	}
+	fmt.Println("Trainee assignments:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	fmt.Printf("%d supervised, %d unsupervised.\n", supervised, unsupervised)
+}
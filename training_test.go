@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnsupervisedTrainees(t *testing.T) {
+	info := Info{Users: []User{
+		{Name: "Tia", Trainee: true},
+		{Name: "Tom", Trainee: true},
+		{Name: "Ann", TrainerFor: []string{"Desk", "EOD Reports"}},
+		{Name: "Bob"},
+	}}
+	tests := []struct {
+		task, cell string
+		want       []string
+	}{
+		{"Desk", "Tia", []string{"Tia"}},
+		{"Desk", "Tia, Bob", []string{"Tia"}},
+		{"Desk", "Tia, Ann", nil},
+		{"Desk", "Ann, Tia, Tom", nil},
+		{"Desk", "Tia, Tom", []string{"Tia", "Tom"}},
+		{"Mail", "Tia, Ann", []string{"Tia"}},
+		{"Late Person Tasks", "Tia, Ann", nil},
+		{"Desk", "Bob", nil},
+	}
+	for _, tt := range tests {
+		if got := unsupervisedTrainees(info, tt.task, tt.cell); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("unsupervisedTrainees(%s, %q) = %v, want %v", tt.task, tt.cell, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// weekendDaysOf returns the configured weekend days, defaulting to Saturday and Sunday.
+func weekendDaysOf(info Info) []string {
+	if len(info.WeekendDays) == 0 {
+		return defaultWeekendDays
+	}
+	return info.WeekendDays
+}
+
+// workedWeekend reports whether name holds any cell on a weekend day of schedule.
+func workedWeekend(info Info, schedule map[string]map[string]string, name string) bool {
+	for _, day := range weekendDaysOf(info) {
+		for _, cell := range schedule[day] {
+			if cellHas(cell, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// weekendLog collects the weekend assignments that had to go to someone who also worked last weekend.
+type weekendLog struct {
+	Forced []string
+}
+
+// restedForWeekend narrows the eligible users for a weekend slot to those who did not work a weekend
+// in the previous schedule. When that leaves nobody, everyone stays eligible and forced is true.
+func restedForWeekend(info Info, day string, users []User, previousSchedule map[string]map[string]string) (rested []User, forced bool) {
+	if !contains(weekendDaysOf(info), day) || previousSchedule == nil {
+		return users, false
+	}
+	for _, user := range users {
+		if !workedWeekend(info, previousSchedule, user.Name) {
+			rested = append(rested, user)
+		}
+	}
+	if len(rested) == 0 {
+		return users, len(users) > 0
+	}
+	return rested, false
+}
+
+// printWeekendReport lists the forced consecutive-weekend assignments, if any.
+func printWeekendReport(log *weekendLog) {
+	if len(log.Forced) == 0 {
+		fmt.Println("No consecutive weekends: rule held for every weekend slot.")
+		return
+	}
+	fmt.Printf("No consecutive weekends: %d forced violation(s), the weekend pool was too small:\n", len(log.Forced))
+	for _, forced := range log.Forced {
+		fmt.Printf("  %s\n", forced)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// parseAbsence parses a -simulate-absence NAME:Day,Day value against the configuration.
+func parseAbsence(query string, info Info) (string, []string, error) {
+	colon := strings.LastIndex(query, ":")
+	if colon < 0 {
+		return "", nil, fmt.Errorf("expected NAME:days, got %q", query)
+	}
+	name := query[:colon]
+	if !rosterHas(info, name) {
+		return "", nil, fmt.Errorf("unknown user %q", name)
+	}
+	days := parseTagList(query[colon+1:])
+	if len(days) == 0 {
+		return "", nil, fmt.Errorf("no days given for %s", name)
+	}
+	for _, day := range days {
+		if !contains(info.DaysOfWeek, day) {
+			return "", nil, fmt.Errorf("unknown day %q", day)
+		}
+	}
+	return name, days, nil
+}
+
+// withAbsence returns a copy of info in which name is also unavailable on days.
+func withAbsence(info Info, name string, days []string) Info {
+	absent := info
+	absent.Users = append([]User{}, info.Users...)
+	for i, user := range absent.Users {
+		if user.Name == name {
+			absent.Users[i].DaysUnavailable = append(append([]string{}, user.DaysUnavailable...), days...)
+		}
+	}
+	return absent
+}
+
+// gapSlots lists the task/day slots filled below the task's minimum.
+func gapSlots(info Info, schedule map[string]map[string]string) []string {
+	var gaps []string
+	for _, task := range info.Tasks {
+		min, _ := taskRange(task)
+		for _, day := range task.Days {
+			if len(cellAssignees(schedule[day][task.Name])) < min {
+				gaps = append(gaps, fmt.Sprintf("%s on %s", task.Name, day))
+			}
+		}
+	}
+	return gaps
+}
+
+// simulateAbsence generates a baseline week, then regenerates it with name out on days while keeping
+// every baseline cell that does not involve the absence, so the comparison shows only the knock-on
+// effect of the leave rather than a reshuffle of the whole week. It prints the cell-level differences
+// and the gaps the absence opens up. Generation logs are silenced so only the comparison is shown.
+func simulateAbsence(info Info, previousSchedule map[string]map[string]string, opts scheduleOptions, seed int64, name string, days []string) {
+	log.SetOutput(io.Discard)
+	generate := func(runInfo Info, fixed map[string]map[string]string) map[string]map[string]string {
+		rng = rand.New(rand.NewSource(seed))
+		runInfo.Users = append([]User{}, runInfo.Users...)
+		runOpts := opts
+		runOpts.Fixed = fixed
+		schedule, _ := generateWeeklySchedule(runInfo, previousSchedule, runOpts)
+		return schedule
+	}
+	baseline := generate(info, nil)
+	absentInfo := withAbsence(info, name, days)
+	absent := generate(absentInfo, unaffectedCells(baseline, info, name, days))
+	log.SetOutput(os.Stderr)
+
+	fmt.Printf("What if %s is out on %s (seed %d):\n\n", name, strings.Join(days, ", "), seed)
+	printScheduleDiff(diffSchedules(baseline, absent, info.DaysOfWeek))
+
+	baselineGaps := make(map[string]bool)
+	for _, gap := range gapSlots(info, baseline) {
+		baselineGaps[gap] = true
+	}
+	var newGaps []string
+	for _, gap := range gapSlots(absentInfo, absent) {
+		if !baselineGaps[gap] {
+			newGaps = append(newGaps, gap)
+		}
+	}
+	if len(newGaps) == 0 {
+		fmt.Println("\nNo new gaps: every slot is still covered.")
+		return
+	}
+	fmt.Printf("\n%d new gap(s):\n", len(newGaps))
+	for _, gap := range newGaps {
+		fmt.Printf("  %s\n", gap)
+	}
+}
+
+// unaffectedCells returns the baseline cells to keep fixed when name is out on days: everything
+// except name's cells on those days, which are left with their other assignees to be topped up, and
+// whole dedicated tasks name holds, since the role has to move to someone else for the week.
+func unaffectedCells(baseline map[string]map[string]string, info Info, name string, days []string) map[string]map[string]string {
+	moving := make(map[string]bool)
+	for _, task := range info.Tasks {
+		if isDedicated(task) {
+			for _, day := range days {
+				if cellHas(baseline[day][task.Name], name) {
+					moving[task.Name] = true
+				}
+			}
+		}
+	}
+
+	fixed := make(map[string]map[string]string)
+	for day, cells := range baseline {
+		fixed[day] = make(map[string]string)
+		for taskName, cell := range cells {
+			if moving[taskName] {
+				continue
+			}
+			if contains(days, day) && cellHas(cell, name) {
+				kept := ""
+				for _, assignee := range cellAssignees(cell) {
+					if assignee != name {
+						kept = addAssignee(kept, assignee)
+					}
+				}
+				if kept == "" {
+					continue
+				}
+				cell = kept
+			}
+			fixed[day][taskName] = cell
+		}
+	}
+	return fixed
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schedule maps a day to the task cells of that day, each holding one or more assignees.
+type Schedule map[string]map[string]string
+
+// Writer writes one schedule, or one split part of it, in a particular output format. info holds the
+// days the part covers along with the roster and tasks.
+type Writer interface {
+	Write(schedule Schedule, info Info) error
+}
+
+// writerFactory builds the Writer for one output file from its rendered name and the output config.
+// Formats registered without an extension get a directory name instead.
+type writerFactory func(filename string, config outputConfig) Writer
+
+// registeredWriter is an output format available to -format.
+type registeredWriter struct {
+	// Extension is appended to default output names; empty for formats that write a directory.
+	Extension string
+	New       writerFactory
+}
+
+// writers holds the output formats by -format name. New formats only need a registerWriter call,
+// typically from an init function in their own file.
+var writers = make(map[string]registeredWriter)
+
+// registerWriter makes a format available to -format under name. Registering a name twice panics.
+func registerWriter(name, extension string, factory writerFactory) {
+	if _, exists := writers[name]; exists {
+		panic(fmt.Sprintf("output format %q registered twice", name))
+	}
+	writers[name] = registeredWriter{Extension: extension, New: factory}
+}
+
+// writerNames lists the registered formats in alphabetical order.
+func writerNames() string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	registerWriter("csv", ".csv", func(filename string, config outputConfig) Writer {
+		return csvWriter{filename, config.TaskLabel}
+	})
+	registerWriter("long", ".csv", func(filename string, config outputConfig) Writer {
+		return longWriter{filename, config.TaskLabel, config.LongOrder}
+	})
+	registerWriter("normalized", "", func(dir string, config outputConfig) Writer {
+		return normalizedWriter{dir}
+	})
+	registerWriter("markdown", ".md", func(filename string, config outputConfig) Writer {
+		return markdownWriter{filename, config.Messages}
+	})
+	registerWriter("html", ".html", func(filename string, config outputConfig) Writer {
+		return htmlWriter{filename, config.Messages}
+	})
+}
+
+// csvWriter writes the task x day grid.
+type csvWriter struct {
+	filename  string
+	taskLabel string
+}
+
+func (w csvWriter) Write(schedule Schedule, info Info) error {
+	return scheduleToCSV(schedule, info.DaysOfWeek, w.taskLabel, w.filename)
+}
+
+// longWriter writes one Day,Task,Assignee row per assignee.
+type longWriter struct {
+	filename  string
+	taskLabel string
+	order     string
+}
+
+func (w longWriter) Write(schedule Schedule, info Info) error {
+	return scheduleToLongCSV(schedule, info.DaysOfWeek, w.order, w.taskLabel, w.filename)
+}
+
+// normalizedWriter writes the assignments, users and tasks tables into a directory.
+type normalizedWriter struct {
+	dir string
+}
+
+func (w normalizedWriter) Write(schedule Schedule, info Info) error {
+	_, err := writeNormalized(schedule, info, w.dir)
+	return err
+}
+
+// markdownWriter writes a titled Markdown table.
+type markdownWriter struct {
+	filename string
+	labels   messages
+}
+
+func (w markdownWriter) Write(schedule Schedule, info Info) error {
+	return scheduleToMarkdown(schedule, info, w.labels, w.filename)
+}
+
+// htmlWriter writes a titled HTML page.
+type htmlWriter struct {
+	filename string
+	labels   messages
+}
+
+func (w htmlWriter) Write(schedule Schedule, info Info) error {
+	return scheduleToHTML(schedule, info, w.labels, w.filename)
+}